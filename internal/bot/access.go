@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+)
+
+// accessRequest is a pending request from an unauthorized user.
+type accessRequest struct {
+	user        telebot.User
+	messages    []*telebot.Message // notifications sent to admins
+	requestedAt time.Time
+}
+
+// accessRequests tracks pending requests and recent denials.
+type accessRequests struct {
+	mu      sync.Mutex
+	pending map[int64]*accessRequest
+	denied  map[int64]time.Time
+}
+
+// newAccessRequests creates an empty access request tracker.
+func newAccessRequests() *accessRequests {
+	return &accessRequests{
+		pending: make(map[int64]*accessRequest),
+		denied:  make(map[int64]time.Time),
+	}
+}
+
+// requestAccessMarkup builds the keyboard offered to unauthorized users.
+func (b *Bot) requestAccessMarkup(userID int64) *telebot.ReplyMarkup {
+	selector := &telebot.ReplyMarkup{}
+	selector.Inline(selector.Row(selector.Data(b.t(userID, "button.request_access"), "request_access")))
+	return selector
+}
+
+// handleAccessRequest forwards an unauthorized user's request to the admins.
+func (b *Bot) handleAccessRequest(c telebot.Context) error {
+	user := c.Sender()
+	log := logger.WithUser(user.ID, user.Username)
+
+	if b.roleOf(user.ID) != config.RoleNone {
+		return c.Edit(b.t(user.ID, "access.already_authorized"))
+	}
+
+	b.access.mu.Lock()
+	if req, ok := b.access.pending[user.ID]; ok && time.Since(req.requestedAt) < constants.AccessRequestTTL {
+		b.access.mu.Unlock()
+		return c.Edit(b.t(user.ID, "access.already_pending"))
+	}
+	if deniedAt, ok := b.access.denied[user.ID]; ok && time.Since(deniedAt) < constants.AccessRequestCooldown {
+		b.access.mu.Unlock()
+		return c.Edit(b.t(user.ID, "access.denied_recently"))
+	}
+	req := &accessRequest{user: *user, requestedAt: time.Now()}
+	b.access.pending[user.ID] = req
+	b.access.mu.Unlock()
+
+	log.Info("access requested")
+
+	id := strconv.FormatInt(user.ID, 10)
+	decisionMarkup := func(adminID int64) *telebot.ReplyMarkup {
+		selector := &telebot.ReplyMarkup{}
+		selector.Inline(selector.Row(
+			selector.Data(b.t(adminID, "button.approve"), "access_approve", id),
+			selector.Data(b.t(adminID, "button.deny"), "access_deny", id),
+		))
+		return selector
+	}
+
+	messages := b.notifyAdmins(decisionMarkup, "access.admin_request", displayName(user), user.ID)
+
+	b.access.mu.Lock()
+	req.messages = messages
+	// Without a notification no admin can decide, so let the user retry
+	if len(messages) == 0 && b.access.pending[user.ID] == req {
+		delete(b.access.pending, user.ID)
+	}
+	b.access.mu.Unlock()
+
+	if len(messages) == 0 {
+		log.Warn("no admin could be notified of access request")
+		return c.Edit(b.t(user.ID, "access.unavailable"))
+	}
+
+	return c.Edit(b.t(user.ID, "access.submitted"))
+}
+
+// handleAccessDecision approves or denies a pending access request.
+func (b *Bot) handleAccessDecision(c telebot.Context, payload string, approve bool) error {
+	admin := c.Sender()
+	log := logger.WithUser(admin.ID, admin.Username)
+
+	if !b.can(admin.ID, config.PermManageUsers) {
+		log.Warn("non-admin attempted access decision")
+		return nil
+	}
+
+	targetID, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		log.Error("invalid access request payload", "payload", payload)
+		return c.Edit(b.t(admin.ID, "error.unknown_action"))
+	}
+
+	b.access.mu.Lock()
+	req := b.access.pending[targetID]
+	delete(b.access.pending, targetID)
+	if !approve {
+		b.access.denied[targetID] = time.Now()
+	}
+	b.access.mu.Unlock()
+
+	// Requests survive restarts only as admin messages, so fall back to the ID
+	name := strconv.FormatInt(targetID, 10)
+	messages := []*telebot.Message{c.Message()}
+	if req != nil {
+		name = displayName(&req.user)
+		messages = req.messages
+	}
+
+	adminKey, userKey := "access.admin_approved", "access.user_approved"
+	if approve {
+		if err := b.config.AddAuthorizedUser(targetID); err != nil && !apperrors.Is(err, apperrors.ErrUserAlreadyExists) {
+			log.WithError(err).Error("failed to authorize user", "target", targetID)
+			return c.Edit(b.errorText(admin.ID, err))
+		}
+	} else {
+		adminKey, userKey = "access.admin_denied", "access.user_denied"
+	}
+
+	log.WithFields(map[string]interface{}{
+		"target":   targetID,
+		"approved": approve,
+	}).Info("access request decided")
+
+	if approve {
+		go b.refreshCommands(targetID)
+	}
+
+	for _, msg := range messages {
+		if msg != nil {
+			b.editMessage(b.ctx, msg, b.t(msg.Chat.ID, adminKey, name, targetID, displayName(admin)))
+		}
+	}
+
+	if _, err := b.sendMessage(b.ctx, &telebot.Chat{ID: targetID}, b.t(targetID, userKey)); err != nil {
+		log.WithError(err).Warn("failed to notify requester", "target", targetID)
+	}
+
+	return nil
+}
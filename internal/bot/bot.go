@@ -2,10 +2,8 @@
 package bot
 
 import (
-	"fmt"
-	"io"
+	"context"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,9 +12,12 @@ import (
 
 	"telegram-cf-bot/internal/cloudflare"
 	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
 	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/i18n"
 	"telegram-cf-bot/internal/logger"
-	"telegram-cf-bot/internal/validator"
+	"telegram-cf-bot/internal/ratelimit"
+	"telegram-cf-bot/internal/storage"
 )
 
 // Bot represents the Telegram bot instance.
@@ -25,10 +26,29 @@ type Bot struct {
 	config         *config.Config
 	cfClient       *cloudflare.Client
 	httpClient     *http.Client
-	pendingUploads map[int64]string
-	uploadMutex    sync.RWMutex
+	pending        *pendingUploads
+	pendingStore   *storage.PendingStore
+	queue          *uploadQueue
+	usage          *storage.UsageStore
+	invites        *storage.InviteStore
+	users          *storage.UserStore
+	settings       *storage.SettingsStore
+	uploadLog      *storage.UploadLog
+	images         *storage.ImageStore
+	alerts         *storage.AlertStore
+	i18n           *i18n.Catalog
+	members        *membershipCache
+	access         *accessRequests
+	ctx            context.Context
+	cancel         context.CancelCauseFunc
+	tgLimiter      *ratelimit.Limiter
+	chatLimiter    *ratelimit.KeyedLimiter
+	refreshMu      sync.Mutex
+	refreshing     bool
+	refreshPending bool
 	stopChan       chan struct{}
 	wg             sync.WaitGroup
+	workerWg       sync.WaitGroup
 }
 
 // New creates a new bot instance.
@@ -42,13 +62,76 @@ func New(cfg *config.Config) (*Bot, error) {
 		return nil, apperrors.Wrap(apperrors.ErrInvalidConfig, "failed to create telegram bot", err)
 	}
 
+	usage, err := storage.NewUsageStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := storage.NewInviteStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := storage.NewUserStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := i18n.Load(cfg.Language.Default, cfg.Language.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	userSettings, err := storage.NewSettingsStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingStore, err := storage.NewPendingStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadLog, err := storage.NewUploadLog(cfg.Storage.DataDir, constants.StatsRetention, constants.StatsCompactLines)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := storage.NewAlertStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := storage.NewImageStore(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+
 	return &Bot{
-		telebot:        tb,
-		config:         cfg,
-		cfClient:       cloudflare.NewClient(cfg),
-		httpClient:     &http.Client{Timeout: 30 * time.Second},
-		pendingUploads: make(map[int64]string),
-		stopChan:       make(chan struct{}),
+		telebot:      tb,
+		config:       cfg,
+		cfClient:     cloudflare.NewClient(cfg),
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		pending:      newPendingUploads(cfg.Upload.PendingTTL, pendingStore),
+		pendingStore: pendingStore,
+		queue:        newUploadQueue(cfg.Upload.QueueSize),
+		usage:        usage,
+		invites:      invites,
+		users:        users,
+		settings:     userSettings,
+		uploadLog:    uploadLog,
+		images:       images,
+		alerts:       alerts,
+		i18n:         catalog,
+		members:      newMembershipCache(cfg.GroupAuth.CacheTTL),
+		access:       newAccessRequests(),
+		ctx:          ctx,
+		cancel:       cancel,
+		tgLimiter:    ratelimit.New(cfg.RateLimit.TelegramGlobal.Rate, cfg.RateLimit.TelegramGlobal.Burst),
+		chatLimiter:  ratelimit.NewKeyed(cfg.RateLimit.TelegramPerChat.Rate, cfg.RateLimit.TelegramPerChat.Burst),
+		stopChan:     make(chan struct{}),
 	}, nil
 }
 
@@ -56,14 +139,42 @@ func New(cfg *config.Config) (*Bot, error) {
 func (b *Bot) Start() error {
 	logger.WithFields(map[string]interface{}{"username": b.telebot.Me.Username}).Info("starting bot")
 
+	b.telebot.Use(b.trackUser)
+
 	// Register handlers
 	b.telebot.Handle("/start", b.handleStart)
+	b.telebot.Handle("/help", b.handleHelp)
 	b.telebot.Handle("/auth", b.handleAuth)
 	b.telebot.Handle("/unauth", b.handleUnauth)
+	b.telebot.Handle("/quota", b.handleQuota)
+	b.telebot.Handle("/images", b.handleImages)
+	b.telebot.Handle("/delete", b.handleDelete)
+	b.telebot.Handle("/role", b.handleRole)
+	b.telebot.Handle("/promote", b.handlePromote)
+	b.telebot.Handle("/demote", b.handleDemote)
+	b.telebot.Handle("/invite", b.handleInvite)
+	b.telebot.Handle("/users", b.handleUsers)
+	b.telebot.Handle("/stats", b.handleStats)
+	b.telebot.Handle("/usage", b.handleUsage)
+	b.telebot.Handle("/settings", b.handleSettings)
+	b.telebot.Handle("/upload", b.handleUpload)
+	b.telebot.Handle("/authchat", b.handleAuthChat)
+	b.telebot.Handle("/unauthchat", b.handleUnauthChat)
 	b.telebot.Handle(telebot.OnPhoto, b.handlePhoto)
 	b.telebot.Handle(telebot.OnDocument, b.handleDocument)
+	b.telebot.Handle(telebot.OnChannelPost, b.handleChannelPost)
 	b.telebot.Handle(telebot.OnCallback, b.handleCallback)
 
+	b.restorePending()
+	b.startWorkers(b.config.Upload.Workers)
+
+	b.wg.Add(5)
+	go b.flushUsers()
+	go b.sweepExpiredUsers()
+	go b.sweepPendingUploads()
+	go b.registerCommands()
+	go b.monitorUsage()
+
 	// Start polling in a goroutine
 	b.wg.Add(1)
 	go func() {
@@ -83,9 +194,38 @@ func (b *Bot) Stop() {
 	close(b.stopChan)
 	b.telebot.Stop()
 	b.wg.Wait()
+
+	// Drain queued and in-flight uploads before exiting
+	b.drainUploads(constants.ShutdownTimeout)
+	b.cancel(apperrors.ErrShuttingDown)
+
+	if err := b.users.Flush(); err != nil {
+		logger.WithError(err).Error("failed to flush user store")
+	}
 	logger.Info("bot stopped")
 }
 
+// notifyAdmins sends every admin the message for key in their language
+// and returns the messages sent. markup, if not nil, builds a keyboard for
+// each admin.
+func (b *Bot) notifyAdmins(markup func(adminID int64) *telebot.ReplyMarkup, key string, args ...interface{}) []*telebot.Message {
+	var sent []*telebot.Message
+	for _, adminID := range b.config.AdminIDs() {
+		var opts []interface{}
+		if markup != nil {
+			opts = append(opts, markup(adminID))
+		}
+
+		msg, err := b.sendMessage(b.ctx, &telebot.Chat{ID: adminID}, b.t(adminID, key, args...), opts...)
+		if err != nil {
+			logger.WithUser(adminID, "").WithError(err).Warn("failed to notify admin")
+			continue
+		}
+		sent = append(sent, msg)
+	}
+	return sent
+}
+
 // handleStart handles the /start command.
 func (b *Bot) handleStart(c telebot.Context) error {
 	userID := c.Sender().ID
@@ -93,71 +233,99 @@ func (b *Bot) handleStart(c telebot.Context) error {
 
 	logger.LogUserAction(userID, username, "command_start", nil)
 
-	if !b.config.IsAuthorized(userID) {
+	if !b.can(userID, config.PermView) {
+		// Deep links carry an invite code as the /start payload
+		if code := c.Message().Payload; code != "" {
+			return b.redeemInvite(c, code)
+		}
+
 		logger.WithUser(userID, username).Warn("unauthorized access attempt")
-		return c.Send("抱歉，您没有使用此机器人的权限。")
+		return c.Send(b.deniedText(userID)+"\n\n"+b.t(userID, "start.request_hint"), b.requestAccessMarkup(userID))
 	}
 
-	return c.Send("欢迎使用 Cloudflare 图片上传机器人。请以文件形式发送图片以保持最佳质量。")
+	return c.Send(b.t(userID, "start.welcome"))
 }
 
 // handlePhoto handles photo messages (compressed images).
 func (b *Bot) handlePhoto(c telebot.Context) error {
+	if isGroupChat(c.Chat()) {
+		return b.handleGroupMedia(c)
+	}
+
 	userID := c.Sender().ID
 	username := c.Sender().Username
 
 	logger.LogUserAction(userID, username, "send_photo", nil)
 
-	if !b.config.IsAuthorized(userID) {
+	if !b.can(userID, config.PermUpload) {
 		logger.WithUser(userID, username).Warn("unauthorized photo upload attempt")
-		return c.Send("抱歉，您没有使用此机器人的权限。")
+		return c.Send(b.deniedText(userID))
 	}
 
 	photo := c.Message().Photo
 	if photo == nil {
-		return c.Send("未检测到图片")
+		return c.Send(b.t(userID, "upload.no_photo"))
 	}
 
-	// Store file ID for later
-	b.uploadMutex.Lock()
-	b.pendingUploads[userID] = photo.FileID
-	b.uploadMutex.Unlock()
+	if b.settings.Get(userID).AutoUpload {
+		return b.enqueueUpload(c, photo.FileID, c.Message().Caption, nil)
+	}
 
-	logger.WithUser(userID, username).Debug("stored photo for confirmation", "file_id", photo.FileID)
+	// Store file ID for later, keyed by a token embedded in the buttons
+	// so each prompt confirms its own photo
+	token := newPendingToken()
 
-	// Create confirmation keyboard
 	selector := &telebot.ReplyMarkup{}
-	btnConfirm := selector.Data("确认上传", "confirm_upload")
-	btnCancel := selector.Data("取消", "cancel_upload")
+	btnConfirm := selector.Data(b.t(userID, "button.confirm_upload"), "confirm_upload", token)
+	btnCancel := selector.Data(b.t(userID, "button.cancel"), "cancel_upload", token)
 	selector.Inline(selector.Row(btnConfirm, btnCancel))
 
-	return c.Send("您发送的是压缩图片，可能会损失质量。确定要上传吗？", selector)
+	prompt, err := b.telebot.Send(c.Chat(), b.t(userID, "confirm.prompt"), selector)
+	if err != nil {
+		return err
+	}
+
+	b.pending.add(token, &pendingUpload{
+		userID:  userID,
+		fileID:  photo.FileID,
+		caption: c.Message().Caption,
+		prompt:  prompt,
+		created: time.Now(),
+	})
+
+	logger.WithUser(userID, username).Debug("stored photo for confirmation", "file_id", photo.FileID, "token", token)
+
+	return nil
 }
 
 // handleDocument handles document messages (files).
 func (b *Bot) handleDocument(c telebot.Context) error {
+	if isGroupChat(c.Chat()) {
+		return b.handleGroupMedia(c)
+	}
+
 	userID := c.Sender().ID
 	username := c.Sender().Username
 
 	logger.LogUserAction(userID, username, "send_document", nil)
 
-	if !b.config.IsAuthorized(userID) {
+	if !b.can(userID, config.PermUpload) {
 		logger.WithUser(userID, username).Warn("unauthorized document upload attempt")
-		return c.Send("抱歉，您没有使用此机器人的权限。")
+		return c.Send(b.deniedText(userID))
 	}
 
 	doc := c.Message().Document
 	if doc == nil {
-		return c.Send("未检测到文件")
+		return c.Send(b.t(userID, "upload.no_file"))
 	}
 
 	// Check if it's an image
 	if !strings.HasPrefix(doc.MIME, "image/") {
 		logger.WithUser(userID, username).Warn("non-image file received", "mime", doc.MIME)
-		return c.Send("请发送图片文件，不支持其他类型的文件。")
+		return c.Send(b.t(userID, "upload.not_image"))
 	}
 
-	return b.processImageUpload(c, doc.FileID)
+	return b.enqueueUpload(c, doc.FileID, c.Message().Caption, nil)
 }
 
 // handleCallback handles inline keyboard callbacks.
@@ -170,177 +338,184 @@ func (b *Bot) handleCallback(c telebot.Context) error {
 	userID := c.Sender().ID
 	username := c.Sender().Username
 
-	// Answer callback to remove loading state
-	c.Respond()
-
 	data := strings.TrimSpace(callback.Data)
 	logger.WithUser(userID, username).Debug("received callback", "data", data)
 
-	switch data {
+	action, payload, _ := strings.Cut(data, "|")
+
+	// Answer callback to remove loading state; reply format buttons answer
+	// themselves so they can show an alert
+	if action != "reply_format" {
+		c.Respond()
+	}
+
+	switch action {
 	case "confirm_upload":
 		logger.LogUserAction(userID, username, "confirm_upload", nil)
 
-		b.uploadMutex.RLock()
-		fileID, exists := b.pendingUploads[userID]
-		b.uploadMutex.RUnlock()
+		if !b.can(userID, config.PermUpload) {
+			logger.WithUser(userID, username).Warn("unauthorized upload confirmation")
+			return c.Edit(b.deniedText(userID))
+		}
 
+		upload, exists := b.pending.take(payload, userID)
 		if !exists {
-			return c.Edit("错误：未找到待处理的图片，请重新发送。")
+			return c.Edit(b.t(userID, "confirm.not_found"))
 		}
 
-		// Clear pending upload
-		b.uploadMutex.Lock()
-		delete(b.pendingUploads, userID)
-		b.uploadMutex.Unlock()
-
-		c.Edit("正在处理图片...")
-		return b.processImageUpload(c, fileID)
+		c.Edit(b.t(userID, "upload.processing"))
+		return b.enqueueUpload(c, upload.fileID, upload.caption, nil)
 
 	case "cancel_upload":
 		logger.LogUserAction(userID, username, "cancel_upload", nil)
 
-		b.uploadMutex.Lock()
-		delete(b.pendingUploads, userID)
-		b.uploadMutex.Unlock()
+		b.pending.take(payload, userID)
 
-		return c.Edit("已取消上传。")
+		return c.Edit(b.t(userID, "upload.canceled"))
+
+	case "settings":
+		logger.LogUserAction(userID, username, "change_setting", map[string]interface{}{"setting": payload})
+		return b.handleSettingsChange(c, payload)
+
+	case "reply_format":
+		return b.handleReplyFormat(c, payload)
+
+	case "request_access":
+		logger.LogUserAction(userID, username, "request_access", nil)
+		return b.handleAccessRequest(c)
+
+	case "access_approve", "access_deny":
+		logger.LogUserAction(userID, username, action, map[string]interface{}{"target": payload})
+		return b.handleAccessDecision(c, payload, action == "access_approve")
+
+	case "users_page":
+		return b.handleUsersPage(c, payload)
+
+	case "revoke_user":
+		logger.LogUserAction(userID, username, "revoke_user", map[string]interface{}{"target": payload})
+		return b.handleRevokeUser(c, payload)
+
+	case "cancel_job":
+		logger.LogUserAction(userID, username, "cancel_job", map[string]interface{}{"job_id": payload})
+
+		// Admins may cancel any job, e.g. uploads started from a channel
+		job, waiting, ok := b.queue.cancel(payload, userID, b.can(userID, config.PermManageUsers))
+		if !ok {
+			logger.WithUser(userID, username).Debug("cancel requested for unknown job", "job_id", payload)
+			return nil
+		}
+
+		// Active jobs report the cancellation from their worker
+		if !waiting {
+			return nil
+		}
+
+		b.forgetJob(job)
+		b.scheduleQueueRefresh()
+		return c.Edit(b.t(userID, "upload.canceled"))
 
 	default:
 		logger.WithUser(userID, username).Warn("unknown callback", "data", data)
-		return c.Edit("未知的操作。")
+		return c.Edit(b.t(userID, "error.unknown_action"))
 	}
 }
 
-// handleAuth handles the /auth command (admin only).
+// handleAuth handles the /auth command (admin only). An optional duration
+// such as 7d grants temporary access.
 func (b *Bot) handleAuth(c telebot.Context) error {
-	return b.handleUserCommand(c, "auth", b.config.AddAuthorizedUser)
+	return b.handleUserCommand(c, "auth", "auth.duration_usage", func(lang string, targetID int64, args []string) (string, error) {
+		if len(args) == 0 {
+			return b.i18n.Text(lang, "auth.added", targetID), b.config.AddAuthorizedUser(targetID)
+		}
+
+		d, err := parseDuration(args[0])
+		if err != nil {
+			return "", apperrors.Wrap(apperrors.ErrInvalidDuration, "use a duration such as 12h or 7d", err)
+		}
+
+		expiresAt := time.Now().Add(d)
+		wasPermanent, err := b.config.AddAuthorizedUserUntil(targetID, expiresAt)
+		if wasPermanent {
+			// Make it obvious that a permanent grant was cut short
+			return b.i18n.Text(lang, "auth.now_temporary", targetID, formatTime(expiresAt)), err
+		}
+		return b.i18n.Text(lang, "auth.added_until", targetID, formatTime(expiresAt)), err
+	})
 }
 
 // handleUnauth handles the /unauth command (admin only).
 func (b *Bot) handleUnauth(c telebot.Context) error {
-	return b.handleUserCommand(c, "unauth", b.config.RemoveAuthorizedUser)
+	return b.handleUserCommand(c, "unauth", "", b.simpleUserOperation(b.config.RemoveAuthorizedUser, "auth.removed"))
 }
 
-// handleUserCommand handles admin user management commands.
-func (b *Bot) handleUserCommand(c telebot.Context, action string, operation func(int64) error) error {
-	userID := c.Sender().ID
-	username := c.Sender().Username
-
-	logger.LogUserAction(userID, username, "command_"+action, nil)
-
-	if !b.config.IsAdmin(userID) {
-		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
-		return c.Send("抱歉，只有管理员可以执行此操作。")
-	}
-
-	args := strings.Fields(c.Text())
-	if len(args) != 2 {
-		return c.Send(fmt.Sprintf("用法: /%s <用户ID>", action))
-	}
+// handlePromote handles the /promote command (admin only).
+func (b *Bot) handlePromote(c telebot.Context) error {
+	return b.handleUserCommand(c, "promote", "", b.simpleUserOperation(b.config.AddAdmin, "auth.promoted"))
+}
 
-	targetID, err := strconv.ParseInt(args[1], 10, 64)
-	if err != nil {
-		logger.WithUser(userID, username).Error("invalid user ID format", "input", args[1])
-		return c.Send("无效的用户ID，请输入数字。")
-	}
+// handleDemote handles the /demote command (admin only).
+func (b *Bot) handleDemote(c telebot.Context) error {
+	return b.handleUserCommand(c, "demote", "", b.simpleUserOperation(b.config.RemoveAdmin, "auth.demoted"))
+}
 
-	if err := operation(targetID); err != nil {
-		logger.WithUser(userID, username).WithError(err).Error(action+" failed", "target", targetID)
-		return c.Send(fmt.Sprintf("操作失败: %s", err.Error()))
-	}
+// userOperation applies a management command to its target, given any
+// extra arguments, and returns the success message in lang.
+type userOperation func(lang string, targetID int64, args []string) (string, error)
 
-	actionText := map[string]string{
-		"auth":   "添加",
-		"unauth": "移除",
+// simpleUserOperation adapts a config method taking only a user ID.
+func (b *Bot) simpleUserOperation(operation func(int64) error, successKey string) userOperation {
+	return func(lang string, targetID int64, _ []string) (string, error) {
+		return b.i18n.Text(lang, successKey, targetID), operation(targetID)
 	}
-
-	logger.WithUser(userID, username).Info(action+" successful", "target", targetID)
-	return c.Send(fmt.Sprintf("用户 %d 已成功%s授权列表。", targetID, actionText[action]))
 }
 
-// processImageUpload handles the complete image upload flow.
-func (b *Bot) processImageUpload(c telebot.Context, fileID string) error {
+// handleUserCommand handles admin user management commands. extraUsageKey
+// names the description of one optional argument after the target, or is
+// empty if the command takes none.
+func (b *Bot) handleUserCommand(c telebot.Context, action, extraUsageKey string, operation userOperation) error {
 	userID := c.Sender().ID
 	username := c.Sender().Username
 
-	log := logger.WithUser(userID, username)
-
-	// Send processing message
-	msg, err := c.Bot().Send(c.Chat(), "正在下载图片...")
-	if err != nil {
-		log.WithError(err).Error("failed to send status message")
-	}
-
-	// Download file from Telegram
-	file, err := c.Bot().FileByID(fileID)
-	if err != nil {
-		if msg != nil {
-			c.Bot().Edit(msg, "错误：无法获取文件信息。")
-		}
-		return apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to get file info", err)
-	}
+	logger.LogUserAction(userID, username, "command_"+action, nil)
 
-	// Download file content
-	fileURL := fmt.Sprintf("%s/file/bot%s/%s", c.Bot().URL, c.Bot().Token, file.FilePath)
-	resp, err := b.httpClient.Get(fileURL)
-	if err != nil {
-		if msg != nil {
-			c.Bot().Edit(msg, "错误：无法下载文件。")
-		}
-		return apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to download file", err)
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
 	}
-	defer resp.Body.Close()
 
-	imageBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		if msg != nil {
-			c.Bot().Edit(msg, "错误：读取文件失败。")
-		}
-		return apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to read file", err)
+	lang := b.lang(userID)
+	usage := b.i18n.Text(lang, "usage.user_command", action)
+	maxArgs := 1
+	if extraUsageKey != "" {
+		usage = b.i18n.Text(lang, "usage.user_command_extra", action, b.i18n.Text(lang, extraUsageKey))
+		maxArgs = 2
 	}
 
-	// Validate image
-	if msg != nil {
-		c.Bot().Edit(msg, "正在验证图片...")
+	args := strings.Fields(c.Text())[1:]
+	if len(args) > maxArgs {
+		return c.Send(usage)
 	}
 
-	validationResult, err := validator.Validate(imageBytes)
-	if err != nil {
-		if msg != nil {
-			c.Bot().Edit(msg, fmt.Sprintf("❌ 验证失败: %s", err.Error()))
+	targetID, rest, errText := b.resolveTarget(c, args)
+	if errText != "" {
+		if len(args) == 0 && !c.Message().IsReply() {
+			return c.Send(usage)
 		}
-		return err
+		logger.WithUser(userID, username).Error("invalid user reference", "input", args)
+		return c.Send(errText)
 	}
 
-	// Upload to Cloudflare
-	if msg != nil {
-		c.Bot().Edit(msg, "正在上传到 Cloudflare...")
+	if len(rest) >= maxArgs {
+		return c.Send(usage)
 	}
 
-	uploadResp, err := b.cfClient.Upload(imageBytes, userID, validationResult.Metadata)
+	successText, err := operation(lang, targetID, rest)
 	if err != nil {
-		if msg != nil {
-			c.Bot().Edit(msg, fmt.Sprintf("❌ 上传失败: %s", err.Error()))
-		}
-		return err
-	}
-
-	// Get image URL
-	imageURL, err := cloudflare.GetImageURL(uploadResp)
-	if err != nil {
-		if msg != nil {
-			c.Bot().Edit(msg, fmt.Sprintf("❌ 获取图片URL失败: %s", err.Error()))
-		}
-		return err
-	}
-
-	// Send success message
-	successText := fmt.Sprintf("✅ 上传成功！\n\n图片URL:\n%s", imageURL)
-	if msg != nil {
-		_, err = c.Bot().Edit(msg, successText)
-	} else {
-		err = c.Send(successText)
+		logger.WithUser(userID, username).WithError(err).Error(action+" failed", "target", targetID)
+		return c.Send(b.errorText(userID, err))
 	}
 
-	return err
+	logger.WithUser(userID, username).Info(action+" successful", "target", targetID)
+	go b.refreshCommands(targetID)
+	return c.Send(successText)
 }
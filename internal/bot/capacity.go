@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/cloudflare"
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	"telegram-cf-bot/internal/logger"
+)
+
+// usagePercent returns the share of allowed images in use.
+func usagePercent(stats *cloudflare.ImageStats) float64 {
+	if stats.Allowed <= 0 {
+		return 0
+	}
+	return float64(stats.Current) * 100 / float64(stats.Allowed)
+}
+
+// handleUsage handles the /usage command (admin only), showing the
+// Cloudflare account's image count against its limit.
+func (b *Bot) handleUsage(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_usage", nil)
+
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, constants.ContextTimeout)
+	defer cancel()
+
+	stats, err := b.cfClient.GetStats(ctx)
+	if err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("failed to fetch cloudflare usage")
+		return c.Send(b.errorText(userID, err))
+	}
+
+	lang := b.lang(userID)
+
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "capacity.title"))
+	sb.WriteString(b.i18n.Text(lang, "capacity.images",
+		stats.Current, stats.Allowed, usagePercent(stats), max(stats.Allowed-stats.Current, 0)))
+
+	if thresholds := b.config.Cloudflare.UsageAlert.Thresholds; len(thresholds) > 0 {
+		parts := make([]string, 0, len(thresholds))
+		for _, t := range thresholds {
+			parts = append(parts, b.i18n.Text(lang, "capacity.percent", t))
+		}
+		sb.WriteString(b.i18n.Text(lang, "capacity.thresholds", strings.Join(parts, ", ")))
+	}
+
+	return c.Send(sb.String())
+}
+
+// monitorUsage periodically checks the Cloudflare account's image count
+// and warns admins as it crosses the configured thresholds.
+func (b *Bot) monitorUsage() {
+	defer b.wg.Done()
+
+	alert := b.config.Cloudflare.UsageAlert
+	if len(alert.Thresholds) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(alert.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		b.checkUsage(alert.Thresholds)
+
+		select {
+		case <-ticker.C:
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// checkUsage warns admins once per threshold crossed. The level drops
+// again when images are deleted, so crossing a threshold a second time
+// warns again.
+func (b *Bot) checkUsage(thresholds []int) {
+	ctx, cancel := context.WithTimeout(b.ctx, constants.ContextTimeout)
+	defer cancel()
+
+	stats, err := b.cfClient.GetStats(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("failed to check cloudflare usage")
+		return
+	}
+
+	percent := usagePercent(stats)
+	level := 0
+	for _, t := range thresholds {
+		if percent >= float64(t) {
+			level = t
+		}
+	}
+
+	previous := b.alerts.UsageLevel()
+	if level == previous {
+		return
+	}
+
+	if err := b.alerts.SetUsageLevel(level); err != nil {
+		logger.WithError(err).Error("failed to save usage alert level")
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"current":  stats.Current,
+		"allowed":  stats.Allowed,
+		"level":    level,
+		"previous": previous,
+	}).Info("cloudflare usage level changed")
+
+	if level > previous {
+		b.notifyAdmins(nil, "capacity.warning", level, stats.Current, stats.Allowed, percent)
+	}
+}
@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	"telegram-cf-bot/internal/logger"
+)
+
+// botCommand describes a command for /help and the Telegram command menu.
+type botCommand struct {
+	name   string
+	perm   config.Permission
+	public bool // available without authorization
+	group  bool // shown in the group chat menu
+}
+
+// botCommands lists the commands in the order they are shown.
+var botCommands = []botCommand{
+	{name: "start", public: true},
+	{name: "help", public: true, group: true},
+	{name: "upload", perm: config.PermUpload, group: true},
+	{name: "settings", perm: config.PermView},
+	{name: "quota", perm: config.PermView},
+	{name: "images", perm: config.PermView},
+	{name: "delete", perm: config.PermUpload},
+	{name: "users", perm: config.PermManageUsers},
+	{name: "stats", perm: config.PermManageUsers},
+	{name: "usage", perm: config.PermManageUsers},
+	{name: "auth", perm: config.PermManageUsers},
+	{name: "unauth", perm: config.PermManageUsers},
+	{name: "role", perm: config.PermManageUsers},
+	{name: "promote", perm: config.PermManageUsers},
+	{name: "demote", perm: config.PermManageUsers},
+	{name: "invite", perm: config.PermManageUsers},
+	{name: "authchat", perm: config.PermManageUsers},
+	{name: "unauthchat", perm: config.PermManageUsers},
+}
+
+// commandsFor returns the commands available to role.
+func commandsFor(role config.Role) []botCommand {
+	var commands []botCommand
+	for _, cmd := range botCommands {
+		if cmd.public || config.RoleHas(role, cmd.perm) {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// menu converts commands to a Telegram command menu in lang.
+func (b *Bot) menu(lang string, commands []botCommand) []telebot.Command {
+	menu := make([]telebot.Command, 0, len(commands))
+	for _, cmd := range commands {
+		menu = append(menu, telebot.Command{Text: cmd.name, Description: b.i18n.Text(lang, "command."+cmd.name)})
+	}
+	return menu
+}
+
+// handleHelp handles the /help command, listing the commands available to
+// the caller's role and the upload limits.
+func (b *Bot) handleHelp(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_help", nil)
+
+	lang := b.lang(userID)
+	role := b.roleOf(userID)
+
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "help.title", b.roleName(lang, role)))
+	for _, cmd := range commandsFor(role) {
+		sb.WriteString(b.i18n.Text(lang, "help.command", cmd.name, b.i18n.Text(lang, "command."+cmd.name)))
+	}
+
+	if role == config.RoleNone {
+		sb.WriteString(b.i18n.Text(lang, "help.request_hint"))
+	} else if config.RoleHas(role, config.PermUpload) {
+		sb.WriteString(b.i18n.Text(lang, "help.upload_hint"))
+	}
+
+	sb.WriteString(b.i18n.Text(lang, "help.limits",
+		supportedFormats(),
+		constants.MaxFileSizeBytes/bytesPerMB,
+		constants.MaxImageDimension,
+		constants.MaxImageArea/1000000,
+		constants.MaxAnimatedArea/1000000))
+
+	return c.Send(sb.String())
+}
+
+// supportedFormats lists the accepted image formats for display.
+func supportedFormats() string {
+	formats := make([]string, 0, len(constants.SupportedImageFormats))
+	for format := range constants.SupportedImageFormats {
+		formats = append(formats, strings.ToUpper(format))
+	}
+	slices.Sort(formats)
+	return strings.Join(formats, ", ")
+}
+
+// registerCommands publishes the command menus on startup: public commands
+// by default, uploads in groups and a role-specific menu in the private
+// chat of every configured user and known member of an authorized group.
+func (b *Bot) registerCommands() {
+	defer b.wg.Done()
+
+	var groupCommands []botCommand
+	for _, cmd := range botCommands {
+		if cmd.group {
+			groupCommands = append(groupCommands, cmd)
+		}
+	}
+
+	for i, lang := range b.i18n.Languages() {
+		var langOpts []interface{}
+		// The fallback language also serves clients in any other language
+		if i > 0 {
+			langOpts = append(langOpts, lang)
+		}
+
+		scopes := []struct {
+			scope    telebot.CommandScopeType
+			commands []botCommand
+		}{
+			{telebot.CommandScopeDefault, commandsFor(config.RoleNone)},
+			{telebot.CommandScopeAllGroupChats, groupCommands},
+		}
+		for _, s := range scopes {
+			opts := append([]interface{}{b.menu(lang, s.commands), telebot.CommandScope{Type: s.scope}}, langOpts...)
+			if err := b.setCommands(opts...); err != nil {
+				logger.WithError(err).Warn("failed to register command menu", "scope", s.scope, "lang", lang)
+			}
+		}
+	}
+
+	for _, userID := range b.config.UserIDs() {
+		select {
+		case <-b.stopChan:
+			return
+		default:
+			b.refreshCommands(userID)
+		}
+	}
+
+	if len(b.config.GroupAuth.Chats) == 0 {
+		return
+	}
+
+	for _, userID := range b.users.IDs() {
+		select {
+		case <-b.stopChan:
+			return
+		default:
+			b.refreshGroupCommands(userID)
+		}
+	}
+}
+
+// refreshGroupCommands sets the menu of a user whose only access comes
+// from group membership. Configured users are handled by refreshCommands,
+// and other users keep the default menu without an extra API call.
+func (b *Bot) refreshGroupCommands(userID int64) {
+	if b.config.RoleOf(userID) != config.RoleNone || b.roleOf(userID) == config.RoleNone {
+		return
+	}
+
+	b.refreshCommands(userID)
+}
+
+// refreshCommands updates the menu in a user's private chat to match their
+// current role and language, removing it once they lose access. It is
+// called in the background after role or language changes.
+func (b *Bot) refreshCommands(userID int64) {
+	scope := telebot.CommandScope{Type: telebot.CommandScopeChat, ChatID: userID}
+
+	var err error
+	if role := b.roleOf(userID); role == config.RoleNone {
+		err = b.deleteCommands(scope)
+	} else {
+		err = b.setCommands(b.menu(b.lang(userID), commandsFor(role)), scope)
+	}
+
+	if err != nil {
+		// Users who never started a chat with the bot cannot have a menu
+		logger.WithUser(userID, "").WithError(err).Debug("failed to update command menu")
+	}
+}
+
+// setCommands calls setMyCommands once the global rate limiter allows it.
+func (b *Bot) setCommands(opts ...interface{}) error {
+	if err := b.tgLimiter.Wait(b.ctx); err != nil {
+		return err
+	}
+	return b.telebot.SetCommands(opts...)
+}
+
+// deleteCommands calls deleteMyCommands once the global rate limiter allows it.
+func (b *Bot) deleteCommands(opts ...interface{}) error {
+	if err := b.tgLimiter.Wait(b.ctx); err != nil {
+		return err
+	}
+	return b.telebot.DeleteCommands(opts...)
+}
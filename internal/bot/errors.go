@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"telegram-cf-bot/internal/constants"
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+)
+
+// errorMessage maps an application error type to a catalog key.
+type errorMessage struct {
+	err  error
+	key  string
+	args []interface{}
+}
+
+// errorMessages lists user-facing texts for application errors. Order
+// matters: wrapped errors match the first entry whose type they carry.
+var errorMessages = []errorMessage{
+	{apperrors.ErrImageTooLarge, "error.image_too_large", []interface{}{constants.MaxFileSizeBytes / bytesPerMB}},
+	{apperrors.ErrImageTooBig, "error.image_too_big", []interface{}{constants.MaxImageDimension, constants.MaxImageArea / 1000000}},
+	{apperrors.ErrInvalidFileFormat, "error.invalid_format", nil},
+	{apperrors.ErrInvalidImage, "error.invalid_image", nil},
+	{apperrors.ErrDownloadFailed, "error.download_failed", nil},
+	{apperrors.ErrDeleteFailed, "error.delete_failed", nil},
+	{apperrors.ErrCloudflareAPI, "error.cloudflare_api", nil},
+	{apperrors.ErrUploadFailed, "error.upload_failed", nil},
+	{apperrors.ErrQuotaExceeded, "error.quota_exceeded", nil},
+	{apperrors.ErrQueueFull, "queue.full", nil},
+	{apperrors.ErrQueueClosed, "queue.closed", nil},
+	{apperrors.ErrUploadCanceled, "upload.canceled", nil},
+	{apperrors.ErrShuttingDown, "upload.interrupted", nil},
+	{context.DeadlineExceeded, "upload.timeout", nil},
+	{apperrors.ErrMissingFileID, "confirm.not_found", nil},
+	{apperrors.ErrAlreadyAdmin, "error.already_admin", nil},
+	{apperrors.ErrNotAdmin, "error.not_admin", nil},
+	{apperrors.ErrLastAdmin, "error.last_admin", nil},
+	{apperrors.ErrTargetIsAdmin, "error.target_is_admin", nil},
+	{apperrors.ErrChatAlreadyExists, "error.chat_exists", nil},
+	{apperrors.ErrChatNotFound, "error.chat_not_found", nil},
+	{apperrors.ErrImageNotFound, "error.image_not_found", nil},
+	{apperrors.ErrUserNotFound, "error.user_not_found", nil},
+	{apperrors.ErrUserAlreadyExists, "error.user_exists", nil},
+	{apperrors.ErrInvalidUserID, "error.invalid_user_id", nil},
+	{apperrors.ErrInvalidRole, "error.invalid_role", nil},
+	{apperrors.ErrInvalidDuration, "error.invalid_duration", []interface{}{int(constants.MaxGrantDuration / (24 * time.Hour))}},
+	{apperrors.ErrInviteExpired, "invite.expired", nil},
+	{apperrors.ErrInviteExhausted, "invite.exhausted", nil},
+	{apperrors.ErrInviteNotFound, "invite.invalid", nil},
+	{apperrors.ErrUnauthorized, "error.not_authorized", nil},
+	{apperrors.ErrInvalidConfig, "error.invalid_config", nil},
+	{apperrors.ErrStorage, "error.storage", nil},
+}
+
+// errorText returns a localized, user-facing description of err with a
+// short reference ID. The full error is logged under that ID so reports
+// from users can be matched to the details, which are never shown to them.
+func (b *Bot) errorText(userID int64, err error) string {
+	ref := newErrorRef()
+
+	logger.WithUser(userID, "").WithError(err).WithField("error_ref", ref).Error("error reported to user")
+
+	lang := b.lang(userID)
+	text := b.i18n.Text(lang, "error.unexpected")
+	for _, m := range errorMessages {
+		if apperrors.Is(err, m.err) {
+			text = b.i18n.Text(lang, m.key, m.args...)
+			break
+		}
+	}
+
+	return text + "\n" + b.i18n.Text(lang, "error.reference", ref)
+}
+
+// newErrorRef generates a short reference ID for an error report.
+func newErrorRef() string {
+	randomBytes := make([]byte, constants.ErrorRefBytes)
+	rand.Read(randomBytes)
+	return strings.ToUpper(hex.EncodeToString(randomBytes))
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/constants"
+)
+
+// parseDuration parses durations like "90m", "12h" or "7d". Days are not
+// supported by time.ParseDuration but are the natural unit for access grants.
+// Durations above constants.MaxGrantDuration are rejected.
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		// Check the day count before multiplying so it cannot overflow
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 || n > int(constants.MaxGrantDuration/(24*time.Hour)) {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 || d > constants.MaxGrantDuration {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	return d, nil
+}
+
+// formatTime formats a timestamp for display to users.
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// parseTags extracts the unique #hashtags from a caption, without the '#'.
+func parseTags(caption string) []string {
+	var tags []string
+	for _, word := range strings.Fields(caption) {
+		tag, ok := strings.CutPrefix(word, "#")
+		tag = strings.TrimRight(tag, ".,;:!?，。；：！？")
+		if !ok || tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// displayName returns a readable name for a Telegram user.
+func displayName(user *telebot.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.Username != "" {
+		if name == "" {
+			return "@" + user.Username
+		}
+		return fmt.Sprintf("%s (@%s)", name, user.Username)
+	}
+	if name == "" {
+		return strconv.FormatInt(user.ID, 10)
+	}
+	return name
+}
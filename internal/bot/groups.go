@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/logger"
+)
+
+// isGroupChat reports whether a chat is a group, supergroup or channel.
+func isGroupChat(chat *telebot.Chat) bool {
+	return chat != nil && chat.Type != telebot.ChatPrivate
+}
+
+// uploaderOf returns who an upload is attributed to. Channel posts have
+// no sender, so they are attributed to the channel itself.
+func uploaderOf(c telebot.Context) (int64, string) {
+	if sender := c.Sender(); sender != nil {
+		return sender.ID, sender.Username
+	}
+	return c.Chat().ID, c.Chat().Title
+}
+
+// addressedToBot reports whether a group message asks the bot to act,
+// either by mentioning it or by replying to one of its messages.
+func (b *Bot) addressedToBot(msg *telebot.Message) bool {
+	if reply := msg.ReplyTo; reply != nil && reply.Sender != nil && reply.Sender.ID == b.telebot.Me.ID {
+		return true
+	}
+
+	text := msg.Text
+	if text == "" {
+		text = msg.Caption
+	}
+
+	mention := "@" + strings.ToLower(b.telebot.Me.Username)
+	return strings.Contains(strings.ToLower(text), mention)
+}
+
+// canInChat reports whether the sender may use perm in the current chat.
+// Groups and channels must be authorized themselves; inside an authorized
+// chat members without a role of their own get the default role, while a
+// role assigned by an admin, even a lower one, still applies.
+func (b *Bot) canInChat(c telebot.Context, perm config.Permission) bool {
+	if !isGroupChat(c.Chat()) {
+		return b.can(c.Sender().ID, perm)
+	}
+
+	if !b.config.IsChatAuthorized(c.Chat().ID) {
+		return false
+	}
+
+	if c.Sender() != nil {
+		if role := b.config.RoleOf(c.Sender().ID); role != config.RoleNone {
+			return config.RoleHas(role, perm)
+		}
+	}
+
+	return config.RoleHas(config.DefaultRole, perm)
+}
+
+// imageFileID returns the file ID of the image in msg, if any.
+func imageFileID(msg *telebot.Message) string {
+	switch {
+	case msg.Photo != nil:
+		return msg.Photo.FileID
+	case msg.Document != nil && strings.HasPrefix(msg.Document.MIME, "image/"):
+		return msg.Document.FileID
+	default:
+		return ""
+	}
+}
+
+// handleGroupMedia handles photos and documents posted in groups and
+// channels, acting only on those addressed to the bot.
+func (b *Bot) handleGroupMedia(c telebot.Context) error {
+	msg := c.Message()
+	if !b.addressedToBot(msg) {
+		return nil
+	}
+
+	return b.uploadFromMessage(c, msg)
+}
+
+// handleUpload handles the /upload command sent as a reply to an image.
+func (b *Bot) handleUpload(c telebot.Context) error {
+	uploaderID, uploaderName := uploaderOf(c)
+	logger.LogUserAction(uploaderID, uploaderName, "command_upload", nil)
+
+	target := c.Message().ReplyTo
+	if target == nil {
+		return c.Reply(b.t(uploaderID, "upload.reply_to_image"))
+	}
+
+	return b.uploadFromMessage(c, target)
+}
+
+// handleChannelPost handles posts in channels, where commands are not
+// routed to their handlers.
+func (b *Bot) handleChannelPost(c telebot.Context) error {
+	msg := c.Message()
+
+	if fields := strings.Fields(msg.Text); len(fields) > 0 {
+		if command, _, _ := strings.Cut(fields[0], "@"); command == "/upload" {
+			return b.handleUpload(c)
+		}
+	}
+
+	if imageFileID(msg) != "" && b.addressedToBot(msg) {
+		return b.uploadFromMessage(c, msg)
+	}
+
+	return nil
+}
+
+// uploadFromMessage checks permissions in the current chat and queues the
+// image contained in msg.
+func (b *Bot) uploadFromMessage(c telebot.Context, msg *telebot.Message) error {
+	uploaderID, uploaderName := uploaderOf(c)
+	log := logger.WithUser(uploaderID, uploaderName).WithField("chat_id", c.Chat().ID)
+
+	if !b.canInChat(c, config.PermUpload) {
+		if isGroupChat(c.Chat()) && !b.config.IsChatAuthorized(c.Chat().ID) {
+			log.Warn("upload attempted in unauthorized chat")
+			return c.Reply(b.t(uploaderID, "groups.not_authorized"))
+		}
+		log.Warn("unauthorized upload attempt")
+		return c.Reply(b.deniedText(uploaderID))
+	}
+
+	fileID := imageFileID(msg)
+	if fileID == "" {
+		return c.Reply(b.t(uploaderID, "upload.no_image_in_message"))
+	}
+
+	return b.enqueueUpload(c, fileID, msg.Caption, msg)
+}
+
+// handleAuthChat handles the /authchat command (admin only).
+func (b *Bot) handleAuthChat(c telebot.Context) error {
+	return b.handleChatCommand(c, "authchat", b.config.AddAuthorizedChat, "groups.authorized")
+}
+
+// handleUnauthChat handles the /unauthchat command (admin only).
+func (b *Bot) handleUnauthChat(c telebot.Context) error {
+	return b.handleChatCommand(c, "unauthchat", b.config.RemoveAuthorizedChat, "groups.revoked")
+}
+
+// handleChatCommand handles group authorization commands. Without an
+// argument the command applies to the group it is sent in.
+func (b *Bot) handleChatCommand(c telebot.Context, action string, operation func(int64) error, successKey string) error {
+	userID, username := uploaderOf(c)
+
+	logger.LogUserAction(userID, username, "command_"+action, nil)
+
+	if c.Sender() == nil || !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
+	}
+
+	args := strings.Fields(c.Text())[1:]
+
+	var chatID int64
+	switch {
+	case len(args) == 1:
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return c.Send(b.t(userID, "groups.invalid_id"))
+		}
+		chatID = id
+	case len(args) == 0 && isGroupChat(c.Chat()):
+		chatID = c.Chat().ID
+	default:
+		return c.Send(b.t(userID, "usage.chat_command", action, action))
+	}
+
+	if err := operation(chatID); err != nil {
+		logger.WithUser(userID, username).WithError(err).Error(action+" failed", "chat_id", chatID)
+		return c.Send(b.errorText(userID, err))
+	}
+
+	logger.WithUser(userID, username).Info(action+" successful", "chat_id", chatID)
+	return c.Send(b.t(userID, successKey, chatID))
+}
@@ -0,0 +1,20 @@
+package bot
+
+// lang returns the language to address a user in: their /settings choice,
+// else their Telegram client language, else the default language.
+func (b *Bot) lang(userID int64) string {
+	if lang := b.settings.Get(userID).Language; lang != "" && b.i18n.Has(lang) {
+		return lang
+	}
+
+	if info, ok := b.users.Get(userID); ok && info.LanguageCode != "" {
+		return b.i18n.Match(info.LanguageCode)
+	}
+
+	return b.i18n.Default()
+}
+
+// t returns the message for key in the user's language.
+func (b *Bot) t(userID int64, key string, args ...interface{}) string {
+	return b.i18n.Text(b.lang(userID), key, args...)
+}
@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/cloudflare"
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+// recordImage remembers a successful upload so it can be listed, searched
+// and deleted later.
+func (b *Bot) recordImage(job *uploadJob, resp *cloudflare.UploadResponse, imageURL string) {
+	err := b.images.Add(storage.Image{
+		ID:         resp.Result.ID,
+		UserID:     job.userID,
+		Filename:   resp.Result.Filename,
+		URL:        imageURL,
+		Format:     job.format,
+		Size:       job.size,
+		Tags:       job.tags,
+		UploadedAt: time.Now(),
+	})
+	if err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Error("failed to record uploaded image", "image_id", resp.Result.ID)
+	}
+}
+
+// handleImages handles the /images command, listing the newest uploads
+// that match the search terms. An @username limits the list to that
+// user's uploads.
+func (b *Bot) handleImages(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_images", nil)
+
+	if !b.can(userID, config.PermView) {
+		logger.WithUser(userID, username).Warn("unauthorized image list request")
+		return c.Send(b.deniedText(userID))
+	}
+
+	lang := b.lang(userID)
+
+	var terms []string
+	var ownerID int64
+	for _, arg := range strings.Fields(c.Text())[1:] {
+		if len(arg) > 1 && strings.HasPrefix(arg, "@") {
+			info, ok := b.users.FindByUsername(arg)
+			if !ok {
+				return c.Send(b.i18n.Text(lang, "target.unknown_username", arg))
+			}
+			ownerID = info.ID
+			continue
+		}
+		terms = append(terms, arg)
+	}
+
+	images := b.images.Search(terms, ownerID)
+	if len(images) == 0 {
+		return c.Send(b.i18n.Text(lang, "images.empty"))
+	}
+
+	shown := min(len(images), constants.ImagesListLimit)
+
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "images.header", shown, len(images)))
+	for i, image := range images[:shown] {
+		info, _ := b.users.Get(image.UserID)
+		name := displayName(&telebot.User{ID: image.UserID, Username: info.Username, FirstName: info.FirstName, LastName: info.LastName})
+		sb.WriteString(b.i18n.Text(lang, "images.entry",
+			i+1, image.ID, name, formatTime(image.UploadedAt), float64(image.Size)/bytesPerMB, image.URL))
+	}
+	if shown < len(images) {
+		sb.WriteString(b.i18n.Text(lang, "images.more"))
+	}
+
+	return c.Send(sb.String(), telebot.NoPreview)
+}
+
+// handleDelete handles the /delete command. Uploaders may delete their own
+// images; deleting anyone else's requires PermDeleteAny.
+func (b *Bot) handleDelete(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_delete", nil)
+
+	if !b.can(userID, config.PermUpload) {
+		logger.WithUser(userID, username).Warn("unauthorized delete request")
+		return c.Send(b.deniedText(userID))
+	}
+
+	lang := b.lang(userID)
+
+	// The image is named by ID or URL, or by replying to the success message
+	var ref string
+	args := strings.Fields(c.Text())[1:]
+	switch {
+	case len(args) == 1:
+		ref = args[0]
+	case len(args) == 0 && c.Message().ReplyTo != nil:
+		ref = imageURLPattern.FindString(c.Message().ReplyTo.Text)
+	}
+
+	imageID := imageIDOf(ref)
+	if imageID == "" {
+		return c.Send(b.i18n.Text(lang, "usage.delete"))
+	}
+
+	image, ok := b.images.Get(imageID)
+	if !ok {
+		return c.Send(b.errorText(userID, apperrors.New(apperrors.ErrImageNotFound, fmt.Sprintf("image %q is not tracked", imageID))))
+	}
+
+	if image.UserID != userID && !b.can(userID, config.PermDeleteAny) {
+		logger.WithUser(userID, username).Warn("attempted to delete another user's image", "image_id", image.ID, "owner", image.UserID)
+		return c.Send(b.i18n.Text(lang, "delete.not_owner"))
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, constants.UploadTimeout)
+	defer cancel()
+
+	if err := b.cfClient.Delete(ctx, image.ID); err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("image deletion failed", "image_id", image.ID)
+		return c.Send(b.errorText(userID, err))
+	}
+
+	// The image is already gone from Cloudflare, so only log a failure here
+	if err := b.images.Remove(image.ID); err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("failed to forget deleted image", "image_id", image.ID)
+	}
+
+	logger.WithUser(userID, username).Info("image deleted", "image_id", image.ID, "owner", image.UserID)
+	return c.Send(b.i18n.Text(lang, "delete.done", image.ID))
+}
+
+// imageIDOf returns the image ID named by ref, which is either an ID or a
+// delivery URL ending in /<image ID>/<variant>.
+func imageIDOf(ref string) string {
+	if !strings.Contains(ref, "://") {
+		return ref
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+// handleInvite handles the /invite command (admin only).
+func (b *Bot) handleInvite(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_invite", nil)
+
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
+	}
+
+	usage := b.t(userID, "invite.usage")
+
+	maxUses := constants.DefaultInviteUses
+	ttl := constants.DefaultInviteTTL
+
+	args := strings.Fields(c.Text())[1:]
+	if len(args) > 2 {
+		return c.Send(usage)
+	}
+
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n <= 0 {
+				return c.Send(usage)
+			}
+			maxUses = n
+			continue
+		}
+
+		d, err := parseDuration(arg)
+		if err != nil {
+			return c.Send(usage)
+		}
+		ttl = d
+	}
+
+	now := time.Now()
+	invite := &storage.Invite{
+		Code:      newInviteCode(),
+		CreatedBy: userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		MaxUses:   maxUses,
+	}
+
+	if err := b.invites.Add(invite); err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("failed to store invite")
+		return c.Send(b.errorText(userID, err))
+	}
+
+	logger.WithUser(userID, username).WithFields(map[string]interface{}{
+		"max_uses":   maxUses,
+		"expires_at": invite.ExpiresAt,
+	}).Info("invite created")
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s", b.telebot.Me.Username, invite.Code)
+	return c.Send(b.t(userID, "invite.created", maxUses, formatTime(invite.ExpiresAt), link))
+}
+
+// redeemInvite authorizes the sender with an invite code from a /start deep link.
+func (b *Bot) redeemInvite(c telebot.Context, code string) error {
+	user := c.Sender()
+	log := logger.WithUser(user.ID, user.Username)
+
+	invite, err := b.invites.Redeem(code, user.ID, time.Now())
+	if err != nil {
+		log.WithError(err).Warn("invite redemption failed")
+
+		switch {
+		case apperrors.Is(err, apperrors.ErrInviteExpired):
+			return c.Send(b.t(user.ID, "invite.expired"))
+		case apperrors.Is(err, apperrors.ErrInviteExhausted):
+			return c.Send(b.t(user.ID, "invite.exhausted"))
+		default:
+			return c.Send(b.t(user.ID, "invite.invalid"))
+		}
+	}
+
+	if err := b.config.AddAuthorizedUser(user.ID); err != nil && !apperrors.Is(err, apperrors.ErrUserAlreadyExists) {
+		log.WithError(err).Error("failed to authorize invited user")
+		return c.Send(b.errorText(user.ID, err))
+	}
+
+	log.WithFields(map[string]interface{}{"invited_by": invite.CreatedBy}).Info("invite redeemed")
+	go b.refreshCommands(user.ID)
+
+	b.notifyAdmins(nil, "invite.admin_redeemed", displayName(user), user.ID, invite.Remaining())
+
+	return c.Send(b.t(user.ID, "invite.redeemed"))
+}
+
+// newInviteCode generates a random code safe for /start deep links.
+func newInviteCode() string {
+	randomBytes := make([]byte, constants.InviteCodeBytes)
+	rand.Read(randomBytes)
+	return hex.EncodeToString(randomBytes)
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/constants"
+	"telegram-cf-bot/internal/logger"
+)
+
+// membershipEntry caches whether a user belongs to an authorized group.
+type membershipEntry struct {
+	member  bool
+	expires time.Time
+}
+
+// membershipCache remembers group membership lookups for a fixed TTL so
+// permission checks do not call getChatMember on every message.
+type membershipCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[int64]membershipEntry
+}
+
+// newMembershipCache creates a cache whose entries live for ttl.
+func newMembershipCache(ttl time.Duration) *membershipCache {
+	return &membershipCache{
+		ttl:     ttl,
+		entries: make(map[int64]membershipEntry),
+	}
+}
+
+// get returns the cached membership and whether it is still fresh.
+func (m *membershipCache) get(userID int64) (bool, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[userID]
+	if !ok || time.Now().After(entry.expires) {
+		delete(m.entries, userID)
+		return false, false
+	}
+
+	return entry.member, true
+}
+
+// set stores a membership result.
+func (m *membershipCache) set(userID int64, member bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[userID] = membershipEntry{
+		member:  member,
+		expires: time.Now().Add(m.ttl),
+	}
+}
+
+// isGroupMember reports whether the user belongs to any authorized group.
+// A negative result is cached only when every group gave a definite
+// answer, so a transient Telegram failure does not lock members out.
+func (b *Bot) isGroupMember(userID int64) bool {
+	if len(b.config.GroupAuth.Chats) == 0 {
+		return false
+	}
+
+	if member, ok := b.members.get(userID); ok {
+		return member
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, constants.ContextTimeout)
+	defer cancel()
+
+	user := &telebot.User{ID: userID}
+	uncertain := false
+	for _, chatID := range b.config.GroupAuth.Chats {
+		if err := b.tgLimiter.Wait(ctx); err != nil {
+			return false
+		}
+
+		member, err := b.telebot.ChatMemberOf(&telebot.Chat{ID: chatID}, user)
+		if err != nil {
+			// Telegram reports users who never joined as an error
+			logger.WithUser(userID, "").WithError(err).Debug("group membership lookup failed", "chat_id", chatID)
+			if !isDefiniteAPIError(err) {
+				uncertain = true
+			}
+			continue
+		}
+
+		if isActiveMember(member) {
+			b.members.set(userID, true)
+			return true
+		}
+	}
+
+	if !uncertain {
+		b.members.set(userID, false)
+	}
+	return false
+}
+
+// unnamedAPIError matches the text of Telegram API errors that telebot
+// has no named error for.
+var unnamedAPIError = regexp.MustCompile(`^telegram: .* \((\d{3})\)$`)
+
+// isDefiniteAPIError reports whether err is Telegram rejecting the request
+// itself, such as an unknown user, rather than a network failure or rate
+// limiting that may succeed on retry.
+func isDefiniteAPIError(err error) bool {
+	var flood telebot.FloodError
+	if errors.As(err, &flood) {
+		return false
+	}
+
+	// A migrated group will never answer under its old ID
+	var group telebot.GroupError
+	if errors.As(err, &group) {
+		return true
+	}
+
+	// Named errors such as telebot.ErrChatNotFound are *telebot.Error values
+	var apiErr *telebot.Error
+	if errors.As(err, &apiErr) {
+		return isRejection(apiErr.Code)
+	}
+
+	// telebot builds every other API error with fmt.Errorf("telegram: %s (%d)")
+	// and no wrapped value, so the status code survives only in the text.
+	// Nothing else produces that exact shape; any other error is transient.
+	m := unnamedAPIError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return false
+	}
+	code, _ := strconv.Atoi(m[1])
+	return isRejection(code)
+}
+
+// isRejection reports whether an API status code means the request itself
+// was refused.
+func isRejection(code int) bool {
+	return code == http.StatusBadRequest || code == http.StatusForbidden
+}
+
+// isActiveMember reports whether a chat member currently belongs to the chat.
+func isActiveMember(member *telebot.ChatMember) bool {
+	switch member.Role {
+	case telebot.Creator, telebot.Administrator, telebot.Member:
+		return true
+	case telebot.Restricted:
+		return member.Member
+	default:
+		return false
+	}
+}
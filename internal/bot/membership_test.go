@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"gopkg.in/telebot.v3"
+)
+
+func TestIsDefiniteAPIError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"named bad request", telebot.ErrChatNotFound, true},
+		{"named forbidden", telebot.ErrNotStartedByUser, true},
+		{"wrapped named error", fmt.Errorf("lookup: %w", telebot.ErrUserIsDeactivated), true},
+		{"typed server error", telebot.NewError(500, "Internal Server Error"), false},
+		{"typed unauthorized", telebot.NewError(401, "Unauthorized"), false},
+		{"flood", telebot.FloodError{RetryAfter: 5}, false},
+		{"unnamed bad request", fmt.Errorf("telegram: %s (%d)", "Bad Request: PARTICIPANT_ID_INVALID", 400), true},
+		{"unnamed forbidden", fmt.Errorf("telegram: %s (%d)", "Forbidden: something new", 403), true},
+		{"unnamed server error", fmt.Errorf("telegram: %s (%d)", "Bad Gateway", 502), false},
+		{"unnamed too many requests", fmt.Errorf("telegram: %s (%d)", "Too Many Requests", 429), false},
+		{"text merely mentioning a code", errors.New("proxy: telegram: refused (400) after retry"), false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDefiniteAPIError(tt.err); got != tt.want {
+				t.Errorf("isDefiniteAPIError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+// pendingUpload is a compressed photo waiting for the user to confirm.
+type pendingUpload struct {
+	userID  int64
+	fileID  string
+	caption string
+	prompt  *telebot.Message // the confirmation prompt
+	created time.Time
+}
+
+// pendingUploads holds photo confirmations keyed by the token embedded in
+// the prompt's buttons, so a user can have several prompts open at once.
+// Entries are written through to disk so prompts survive restarts.
+type pendingUploads struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*pendingUpload
+	store   *storage.PendingStore
+}
+
+// newPendingUploads creates a store whose entries expire after ttl.
+func newPendingUploads(ttl time.Duration, store *storage.PendingStore) *pendingUploads {
+	return &pendingUploads{
+		ttl:     ttl,
+		entries: make(map[string]*pendingUpload),
+		store:   store,
+	}
+}
+
+// add stores a pending upload under token.
+func (p *pendingUploads) add(token string, upload *pendingUpload) {
+	p.mu.Lock()
+	p.entries[token] = upload
+	p.mu.Unlock()
+
+	confirmation := &storage.PendingConfirmation{
+		Token:     token,
+		UserID:    upload.userID,
+		FileID:    upload.fileID,
+		Caption:   upload.caption,
+		CreatedAt: upload.created,
+	}
+	if upload.prompt != nil {
+		confirmation.ChatID = upload.prompt.Chat.ID
+		confirmation.PromptID = upload.prompt.ID
+	}
+
+	if err := p.store.AddConfirmation(confirmation); err != nil {
+		logger.WithUser(upload.userID, "").WithError(err).Error("failed to persist pending upload")
+	}
+}
+
+// forget removes token from disk.
+func (p *pendingUploads) forget(token string) {
+	if err := p.store.RemoveConfirmation(token); err != nil {
+		logger.WithError(err).Error("failed to remove persisted pending upload")
+	}
+}
+
+// take removes and returns the pending upload for token if it belongs to
+// userID and has not expired.
+func (p *pendingUploads) take(token string, userID int64) (*pendingUpload, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	upload, ok := p.entries[token]
+	if !ok || upload.userID != userID {
+		return nil, false
+	}
+
+	delete(p.entries, token)
+	p.forget(token)
+
+	if time.Since(upload.created) > p.ttl {
+		return nil, false
+	}
+
+	return upload, true
+}
+
+// expire removes and returns every entry older than the TTL.
+func (p *pendingUploads) expire(now time.Time) []*pendingUpload {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var expired []*pendingUpload
+	for token, upload := range p.entries {
+		if now.Sub(upload.created) > p.ttl {
+			expired = append(expired, upload)
+			delete(p.entries, token)
+			p.forget(token)
+		}
+	}
+
+	return expired
+}
+
+// sweepPendingUploads periodically expires stale photo confirmations
+// until the bot stops.
+func (b *Bot) sweepPendingUploads() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(constants.PendingSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.expirePendingUploads()
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// expirePendingUploads drops stale confirmations and updates their prompts
+// so the buttons no longer look usable.
+func (b *Bot) expirePendingUploads() {
+	expired := b.pending.expire(time.Now())
+	if len(expired) == 0 {
+		return
+	}
+
+	logger.WithFields(logger.Fields{"count": len(expired)}).Debug("expired pending uploads")
+
+	for _, upload := range expired {
+		if upload.prompt == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(b.ctx, constants.ContextTimeout)
+		if _, err := b.editMessage(ctx, upload.prompt, b.t(upload.userID, "confirm.expired")); err != nil {
+			logger.WithUser(upload.userID, "").WithError(err).Debug("failed to update expired prompt")
+		}
+		cancel()
+	}
+}
+
+// restorePending reloads confirmations and queued uploads saved before the
+// last shutdown. Prompts that can no longer be honoured are edited so users
+// know to send the image again.
+func (b *Bot) restorePending() {
+	now := time.Now()
+
+	confirmations := 0
+	for _, saved := range b.pendingStore.Confirmations() {
+		prompt := storedMessage(saved.ChatID, saved.PromptID)
+
+		if now.Sub(saved.CreatedAt) > b.config.Upload.PendingTTL {
+			b.pending.forget(saved.Token)
+			b.editOrphan(saved.UserID, prompt, "confirm.expired")
+			continue
+		}
+
+		b.pending.mu.Lock()
+		b.pending.entries[saved.Token] = &pendingUpload{
+			userID:  saved.UserID,
+			fileID:  saved.FileID,
+			caption: saved.Caption,
+			prompt:  prompt,
+			created: saved.CreatedAt,
+		}
+		b.pending.mu.Unlock()
+		confirmations++
+	}
+
+	restored := 0
+	for _, saved := range b.pendingStore.Uploads() {
+		ctx, cancel := context.WithCancelCause(b.ctx)
+		job := &uploadJob{
+			id:       saved.ID,
+			userID:   saved.UserID,
+			username: saved.Username,
+			uploader: saved.Uploader,
+			chat:     &telebot.Chat{ID: saved.ChatID, Type: telebot.ChatType(saved.ChatType)},
+			fileID:   saved.FileID,
+			tags:     saved.Tags,
+			status:   storedMessage(saved.ChatID, saved.StatusID),
+			queuedAt: saved.QueuedAt,
+			ctx:      ctx,
+			cancel:   cancel,
+		}
+
+		// The image may already be stored, and sending it again would
+		// duplicate it, so leave it to the user
+		if saved.Sending {
+			cancel(nil)
+			b.forgetJob(job)
+			b.editOrphan(job.userID, job.status, "upload.interrupted_uploading")
+			continue
+		}
+
+		if !b.mayResume(job) {
+			cancel(nil)
+			b.forgetJob(job)
+			b.editOrphan(job.userID, job.status, "upload.orphaned")
+			continue
+		}
+
+		if _, _, err := b.queue.push(job); err != nil {
+			cancel(err)
+			b.forgetJob(job)
+			b.editOrphan(job.userID, job.status, "upload.orphaned")
+			continue
+		}
+		restored++
+	}
+
+	logger.WithFields(logger.Fields{
+		"confirmations": confirmations,
+		"uploads":       restored,
+	}).Info("restored pending state")
+
+	if restored > 0 {
+		b.scheduleQueueRefresh()
+	}
+}
+
+// mayResume reports whether a saved job's uploader may still upload.
+func (b *Bot) mayResume(job *uploadJob) bool {
+	if isGroupChat(job.chat) {
+		return b.config.IsChatAuthorized(job.chat.ID)
+	}
+	return b.can(job.userID, config.PermUpload)
+}
+
+// persistJob saves a queued job so it can be resumed after a restart.
+func (b *Bot) persistJob(job *uploadJob) {
+	saved := &storage.QueuedUpload{
+		ID:       job.id,
+		UserID:   job.userID,
+		Username: job.username,
+		Uploader: job.uploader,
+		ChatID:   job.chat.ID,
+		ChatType: string(job.chat.Type),
+		FileID:   job.fileID,
+		Tags:     job.tags,
+		QueuedAt: job.queuedAt,
+	}
+	if job.status != nil {
+		saved.StatusID = job.status.ID
+	}
+
+	if err := b.pendingStore.AddUpload(saved); err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Error("failed to persist queued upload")
+	}
+}
+
+// markSending records on disk that a job is about to send its image to
+// Cloudflare.
+func (b *Bot) markSending(job *uploadJob) {
+	job.sending = true
+	if err := b.pendingStore.MarkSending(job.id); err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Error("failed to persist upload progress")
+	}
+}
+
+// forgetJob removes a finished or abandoned job from disk.
+func (b *Bot) forgetJob(job *uploadJob) {
+	if err := b.pendingStore.RemoveUpload(job.id); err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Error("failed to remove persisted upload")
+	}
+}
+
+// editOrphan tells a user that a prompt or upload left over from before a
+// restart can no longer be used.
+func (b *Bot) editOrphan(userID int64, msg *telebot.Message, key string) {
+	if msg == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, constants.ContextTimeout)
+	defer cancel()
+
+	if _, err := b.editMessage(ctx, msg, b.t(userID, key)); err != nil {
+		logger.WithUser(userID, "").WithError(err).Debug("failed to update orphaned message")
+	}
+}
+
+// storedMessage rebuilds a reference to a sent message from its IDs.
+func storedMessage(chatID int64, messageID int) *telebot.Message {
+	if messageID == 0 {
+		return nil
+	}
+	return &telebot.Message{ID: messageID, Chat: &telebot.Chat{ID: chatID}}
+}
+
+// newPendingToken generates a random token for confirmation callback data.
+func newPendingToken() string {
+	randomBytes := make([]byte, constants.PendingTokenBytes)
+	rand.Read(randomBytes)
+	return hex.EncodeToString(randomBytes)
+}
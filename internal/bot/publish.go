@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/constants"
+	"telegram-cf-bot/internal/logger"
+)
+
+// publishUpload posts a successful upload to the configured gallery
+// channel. Failures are logged but do not affect the upload itself.
+func (b *Bot) publishUpload(job *uploadJob, imageURL string) {
+	channelID := b.config.PublishChannelFor(job.userID)
+	if channelID == 0 || channelID == job.chat.ID {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ContextTimeout)
+	defer cancel()
+
+	_, err := b.sendMessage(ctx, &telebot.Chat{ID: channelID}, b.publishText(channelID, job, imageURL))
+	if err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Warn("failed to publish upload", "channel_id", channelID)
+		return
+	}
+
+	logger.WithUser(job.userID, job.username).Debug("upload published", "channel_id", channelID)
+}
+
+// publishText formats the channel post for an upload.
+func (b *Bot) publishText(channelID int64, job *uploadJob, imageURL string) string {
+	var sb strings.Builder
+	sb.WriteString(imageURL)
+	sb.WriteString(b.t(channelID, "publish.uploader", job.uploader))
+
+	if len(job.tags) > 0 {
+		sb.WriteString(b.t(channelID, "publish.tags"))
+		for i, tag := range job.tags {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString("#" + tag)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,332 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+)
+
+// uploadJob represents a single queued image upload.
+type uploadJob struct {
+	id       string
+	userID   int64
+	username string
+	uploader string // display name used when publishing
+	chat     *telebot.Chat
+	fileID   string
+	tags     []string
+	status   *telebot.Message
+	queuedAt time.Time
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+
+	// Filled in while processing, for the upload log
+	size     int64
+	format   string
+	sending  bool // the image was handed to Cloudflare
+	uploaded bool
+}
+
+// uploadQueue is a bounded FIFO of upload jobs consumed by a fixed worker pool.
+type uploadQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    []*uploadJob
+	active  map[*uploadJob]struct{}
+	maxSize int
+	closed  bool
+}
+
+// newUploadQueue creates an upload queue holding at most maxSize waiting jobs.
+func newUploadQueue(maxSize int) *uploadQueue {
+	q := &uploadQueue{
+		active:  make(map[*uploadJob]struct{}),
+		maxSize: maxSize,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push appends a job and returns its 1-based position and the queue length.
+func (q *uploadQueue) push(job *uploadJob) (int, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, 0, apperrors.New(apperrors.ErrQueueClosed, "upload queue is shut down")
+	}
+
+	if len(q.jobs) >= q.maxSize {
+		return 0, 0, apperrors.New(apperrors.ErrQueueFull, fmt.Sprintf("upload queue is full (%d jobs)", q.maxSize))
+	}
+
+	q.jobs = append(q.jobs, job)
+	q.cond.Signal()
+
+	return len(q.jobs), len(q.jobs), nil
+}
+
+// pop blocks until a job is available and marks it active. It returns
+// false once the queue is closed and fully drained.
+func (q *uploadQueue) pop() (*uploadJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.jobs) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+
+	if len(q.jobs) == 0 {
+		return nil, false
+	}
+
+	job := q.jobs[0]
+	q.jobs[0] = nil
+	q.jobs = q.jobs[1:]
+	q.active[job] = struct{}{}
+
+	return job, true
+}
+
+// finish marks an active job as completed.
+func (q *uploadQueue) finish(job *uploadJob) {
+	q.mu.Lock()
+	delete(q.active, job)
+	q.mu.Unlock()
+}
+
+// cancel aborts the job with the given ID if it belongs to userID, or any
+// job with that ID when force is set. Waiting
+// jobs are removed from the queue; active jobs have their context
+// cancelled and report the cancellation themselves. The returned flag is
+// true when the job was still waiting.
+func (q *uploadQueue) cancel(id string, userID int64, force bool) (*uploadJob, bool, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.jobs {
+		if job.id == id && (force || job.userID == userID) {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			job.cancel(apperrors.ErrUploadCanceled)
+			return job, true, true
+		}
+	}
+
+	for job := range q.active {
+		if job.id == id && (force || job.userID == userID) {
+			job.cancel(apperrors.ErrUploadCanceled)
+			return job, false, true
+		}
+	}
+
+	return nil, false, false
+}
+
+// abandon drops every waiting job and returns them.
+func (q *uploadQueue) abandon() []*uploadJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := q.jobs
+	q.jobs = nil
+
+	return jobs
+}
+
+// waiting returns a snapshot of the jobs still waiting for a worker.
+func (q *uploadQueue) waiting() []*uploadJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]*uploadJob, len(q.jobs))
+	copy(jobs, q.jobs)
+	return jobs
+}
+
+// close stops accepting new jobs and wakes idle workers so they can drain.
+func (q *uploadQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	q.cond.Broadcast()
+}
+
+// startWorkers launches the upload worker pool.
+func (b *Bot) startWorkers(n int) {
+	for i := 0; i < n; i++ {
+		b.workerWg.Add(1)
+		go b.uploadWorker(i)
+	}
+
+	logger.WithFields(logger.Fields{"workers": n}).Info("upload workers started")
+}
+
+// uploadWorker processes queued uploads until the queue is closed and drained.
+func (b *Bot) uploadWorker(id int) {
+	defer b.workerWg.Done()
+
+	for {
+		job, ok := b.queue.pop()
+		if !ok {
+			logger.WithFields(logger.Fields{"worker": id}).Debug("upload worker exiting")
+			return
+		}
+
+		b.scheduleQueueRefresh()
+
+		started := time.Now()
+		err := b.processImageUpload(job)
+		if err != nil {
+			logger.WithUser(job.userID, job.username).WithError(err).Error("upload job failed")
+		}
+		b.recordAttempt(job, err, time.Since(started))
+
+		// Jobs interrupted by shutdown before reaching Cloudflare stay on disk
+		// and resume after restart; ones already sent might be uploaded twice
+		if err == nil || job.sending || !apperrors.Is(context.Cause(job.ctx), apperrors.ErrShuttingDown) {
+			b.forgetJob(job)
+		}
+
+		b.queue.finish(job)
+		job.cancel(nil)
+	}
+}
+
+// drainUploads stops accepting uploads and waits up to timeout for queued
+// and in-flight jobs to finish. After the timeout in-flight requests are
+// cancelled and jobs left unfinished have their status message updated so
+// users are not left with a stale progress message. Unfinished jobs that
+// had not yet sent their image remain persisted and are resumed on the
+// next start.
+func (b *Bot) drainUploads(timeout time.Duration) {
+	b.queue.close()
+
+	done := make(chan struct{})
+	go func() {
+		b.workerWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("all uploads finished")
+		return
+	case <-time.After(timeout):
+	}
+
+	jobs := b.queue.abandon()
+	logger.WithFields(logger.Fields{"waiting": len(jobs)}).Warn("shutdown timeout reached, interrupting uploads")
+
+	// Active jobs observe the cancellation and report it themselves
+	b.cancel(apperrors.ErrShuttingDown)
+
+	for _, job := range jobs {
+		if job.status != nil {
+			b.editMessage(context.Background(), job.status, b.t(job.userID, "upload.interrupted"))
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Warn("upload workers did not stop in time")
+	}
+}
+
+// enqueueUpload adds an upload to the queue and tells the user their
+// position. Hashtags in caption become the upload's tags. In groups the
+// status message replies to replyTo so it is clear which image it belongs to.
+func (b *Bot) enqueueUpload(c telebot.Context, fileID, caption string, replyTo *telebot.Message) error {
+	userID, username := uploaderOf(c)
+
+	uploader := username
+	if sender := c.Sender(); sender != nil {
+		uploader = displayName(sender)
+	}
+
+	ctx, cancel := context.WithCancelCause(b.ctx)
+	job := &uploadJob{
+		id:       newJobID(),
+		userID:   userID,
+		username: username,
+		uploader: uploader,
+		chat:     c.Chat(),
+		fileID:   fileID,
+		tags:     parseTags(caption),
+		queuedAt: time.Now(),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	// Send the status message before queuing so a worker always has it.
+	pending := len(b.queue.waiting()) + 1
+	msg, err := b.sendMessage(ctx, c.Chat(), b.queuePositionText(job, pending, pending), &telebot.SendOptions{
+		ReplyTo:     replyTo,
+		ReplyMarkup: b.cancelMarkup(job),
+	})
+	if err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("failed to send status message")
+	}
+	job.status = msg
+
+	// Persist before queuing so a worker never finishes a job not yet saved
+	b.persistJob(job)
+
+	pos, total, err := b.queue.push(job)
+	if err != nil {
+		cancel(err)
+		b.forgetJob(job)
+		logger.WithUser(userID, username).WithError(err).Warn("failed to enqueue upload")
+		text := b.errorText(userID, err)
+		if msg != nil {
+			_, err = b.editMessage(context.Background(), msg, text)
+			return err
+		}
+		return c.Send(text)
+	}
+
+	logger.WithUser(userID, username).WithFields(map[string]interface{}{
+		"position": pos,
+		"total":    total,
+	}).Debug("upload queued")
+
+	if msg != nil && pos != pending {
+		b.editMessage(ctx, msg, b.queuePositionText(job, pos, total), b.cancelMarkup(job))
+	}
+
+	return nil
+}
+
+// refreshQueuePositions updates the status message of waiting jobs. The
+// edits are skipped in chats without spare rate limit, so a large batch
+// from one chat does not hold up the workers' own progress edits there;
+// skipped positions are caught up on a later refresh.
+func (b *Bot) refreshQueuePositions() {
+	jobs := b.queue.waiting()
+	for i, job := range jobs {
+		if job.status != nil {
+			b.editMessageIfIdle(job.ctx, job.status, b.queuePositionText(job, i+1, len(jobs)), b.cancelMarkup(job))
+		}
+	}
+}
+
+// queuePositionText formats the queue position shown to a job's owner.
+func (b *Bot) queuePositionText(job *uploadJob, pos, total int) string {
+	return b.t(job.userID, "queue.position", pos, total)
+}
+
+// newJobID generates a short random identifier for callback data.
+func newJobID() string {
+	randomBytes := make([]byte, 4)
+	rand.Read(randomBytes)
+	return hex.EncodeToString(randomBytes)
+}
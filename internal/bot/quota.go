@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// reserveQuota reserves an upload of size bytes against the user's quota
+// until releaseQuota is called. It returns a user-facing reason if the
+// upload would exceed the quota, or an empty string if it was reserved.
+func (b *Bot) reserveQuota(userID int64, size int64) string {
+	limits := b.config.QuotaFor(userID)
+
+	var reason string
+	b.usage.Reserve(userID, size, time.Now(), func(usage storage.Usage) bool {
+		reason = b.quotaExceeded(userID, limits, usage, size)
+		return reason == ""
+	})
+
+	return reason
+}
+
+// releaseQuota ends a reservation made by reserveQuota.
+func (b *Bot) releaseQuota(userID int64, size int64) {
+	b.usage.Release(userID, size)
+}
+
+// quotaExceeded returns a user-facing reason if uploading size more bytes
+// on top of usage would exceed limits, or an empty string if it is allowed.
+func (b *Bot) quotaExceeded(userID int64, limits config.QuotaLimits, usage storage.Usage, size int64) string {
+	switch {
+	case limits.DailyCount > 0 && usage.DayCount >= limits.DailyCount:
+		return b.t(userID, "quota.daily_count", limits.DailyCount)
+	case limits.DailyMB > 0 && usage.DayBytes+size > limits.DailyMB*bytesPerMB:
+		return b.t(userID, "quota.daily_mb", limits.DailyMB)
+	case limits.MonthlyCount > 0 && usage.MonthCount >= limits.MonthlyCount:
+		return b.t(userID, "quota.monthly_count", limits.MonthlyCount)
+	case limits.MonthlyMB > 0 && usage.MonthBytes+size > limits.MonthlyMB*bytesPerMB:
+		return b.t(userID, "quota.monthly_mb", limits.MonthlyMB)
+	}
+
+	return ""
+}
+
+// recordUsage adds a successful upload to the user's quota usage.
+func (b *Bot) recordUsage(userID int64, size int64) {
+	if err := b.usage.Record(userID, size, time.Now()); err != nil {
+		logger.WithUser(userID, "").WithError(err).Error("failed to record upload usage")
+	}
+}
+
+// handleQuota handles the /quota command.
+func (b *Bot) handleQuota(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_quota", nil)
+
+	if !b.can(userID, config.PermView) {
+		logger.WithUser(userID, username).Warn("unauthorized quota request")
+		return c.Send(b.deniedText(userID))
+	}
+
+	limits := b.config.QuotaFor(userID)
+	usage := b.usage.Get(userID, time.Now())
+
+	lang := b.lang(userID)
+
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "quota.title"))
+	sb.WriteString(b.i18n.Text(lang, "quota.today",
+		b.formatCount(lang, usage.DayCount, limits.DailyCount), b.formatMB(lang, usage.DayBytes, limits.DailyMB)))
+	sb.WriteString(b.i18n.Text(lang, "quota.month",
+		b.formatCount(lang, usage.MonthCount, limits.MonthlyCount), b.formatMB(lang, usage.MonthBytes, limits.MonthlyMB)))
+
+	return c.Send(sb.String())
+}
+
+// formatCount formats used/limit image counts with the remaining allowance.
+func (b *Bot) formatCount(lang string, used, limit int) string {
+	if limit <= 0 {
+		return b.i18n.Text(lang, "quota.count_unlimited", used)
+	}
+
+	return b.i18n.Text(lang, "quota.count", used, limit, max(limit-used, 0))
+}
+
+// formatMB formats used bytes against a limit in megabytes.
+func (b *Bot) formatMB(lang string, used int64, limitMB int64) string {
+	usedMB := float64(used) / bytesPerMB
+	if limitMB <= 0 {
+		return b.i18n.Text(lang, "quota.mb_unlimited", usedMB)
+	}
+
+	remaining := max(float64(limitMB)-usedMB, 0)
+	return b.i18n.Text(lang, "quota.mb", usedMB, limitMB, remaining)
+}
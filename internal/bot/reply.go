@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/logger"
+)
+
+// replyFormat selects how the success message presents an image URL.
+type replyFormat string
+
+// Available reply formats.
+const (
+	formatURL      replyFormat = "url"
+	formatMarkdown replyFormat = "markdown"
+	formatHTML     replyFormat = "html"
+	formatBBCode   replyFormat = "bbcode"
+	formatAll      replyFormat = "all"
+)
+
+// replyFormats lists the formats in the order they are offered to users.
+var replyFormats = []replyFormat{formatURL, formatMarkdown, formatHTML, formatBBCode, formatAll}
+
+// imageURLPattern finds the image URL in a rendered success message.
+var imageURLPattern = regexp.MustCompile(`https?://[^\s"'()<>\[\]]+`)
+
+// parseReplyFormat returns the named format, defaulting to a plain URL.
+func parseReplyFormat(name string) replyFormat {
+	format := replyFormat(name)
+	if !slices.Contains(replyFormats, format) {
+		return formatURL
+	}
+	return format
+}
+
+// snippet renders imageURL in a single format.
+func snippet(format replyFormat, imageURL string) string {
+	switch format {
+	case formatMarkdown:
+		return fmt.Sprintf("![](%s)", imageURL)
+	case formatHTML:
+		return fmt.Sprintf(`<img src="%s">`, imageURL)
+	case formatBBCode:
+		return fmt.Sprintf("[img]%s[/img]", imageURL)
+	default:
+		return imageURL
+	}
+}
+
+// formatName returns the display name of a format.
+func (b *Bot) formatName(lang string, format replyFormat) string {
+	return b.i18n.Text(lang, "format."+string(format))
+}
+
+// successText formats the message sent after a successful upload. It is
+// HTML so snippets appear in code blocks that copy with one tap.
+func (b *Bot) successText(lang string, format replyFormat, imageURL string) string {
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "upload.success"))
+
+	if format != formatAll {
+		fmt.Fprintf(&sb, "\n<code>%s</code>", html.EscapeString(snippet(format, imageURL)))
+		return sb.String()
+	}
+
+	for _, f := range replyFormats {
+		if f == formatAll {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n<b>%s</b>\n<code>%s</code>\n", b.formatName(lang, f), html.EscapeString(snippet(f, imageURL)))
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// replyFormatMarkup builds the buttons that switch a success message
+// between formats, marking the current one. ownerID is encoded in the
+// buttons so only the uploader can use them in a shared chat.
+func (b *Bot) replyFormatMarkup(lang string, current replyFormat, ownerID int64) *telebot.ReplyMarkup {
+	selector := &telebot.ReplyMarkup{}
+
+	buttons := make([]telebot.Btn, 0, len(replyFormats))
+	for _, f := range replyFormats {
+		label := b.formatName(lang, f)
+		if f == current {
+			label = "• " + label
+		}
+		buttons = append(buttons, selector.Data(label, "reply_format", string(f), strconv.FormatInt(ownerID, 10)))
+	}
+
+	selector.Inline(selector.Row(buttons...))
+	return selector
+}
+
+// handleReplyFormat re-renders a success message in another format. The
+// URL is read back from the message, so this works for any past upload.
+// Only the uploader, or an admin, may switch the format; the callback is
+// answered here so others get an alert.
+func (b *Bot) handleReplyFormat(c telebot.Context, payload string) error {
+	userID := c.Sender().ID
+	name, ownerText, _ := strings.Cut(payload, "|")
+
+	// Buttons from before the owner was encoded are only trusted in private chats
+	ownerID, err := strconv.ParseInt(ownerText, 10, 64)
+	legacy := err != nil
+	owned := ownerID == userID || (legacy && c.Chat().Type == telebot.ChatPrivate)
+	if !owned && !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, c.Sender().Username).Warn("attempted to change another user's reply format", "owner", ownerText)
+		return c.Respond(&telebot.CallbackResponse{Text: b.t(userID, "error.not_your_message"), ShowAlert: true})
+	}
+	c.Respond()
+
+	imageURL := imageURLPattern.FindString(c.Message().Text)
+	if imageURL == "" {
+		return nil
+	}
+	if legacy && c.Chat().Type == telebot.ChatPrivate {
+		ownerID = userID
+	}
+
+	// Pressing the current format leaves the message unchanged
+	lang := b.lang(userID)
+	format := parseReplyFormat(name)
+	err = c.Edit(b.successText(lang, format, imageURL), telebot.ModeHTML, b.replyFormatMarkup(lang, format, ownerID))
+	if errors.Is(err, telebot.ErrSameMessageContent) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"strings"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/logger"
+)
+
+// can reports whether a user holds perm. Every handler checks access
+// through this function.
+func (b *Bot) can(userID int64, perm config.Permission) bool {
+	return config.RoleHas(b.roleOf(userID), perm)
+}
+
+// roleOf resolves a user's role from the config, falling back to group
+// membership for users without one.
+func (b *Bot) roleOf(userID int64) config.Role {
+	if role := b.config.RoleOf(userID); role != config.RoleNone {
+		return role
+	}
+
+	if b.isGroupMember(userID) {
+		return b.config.GroupAuth.Role
+	}
+
+	return config.RoleNone
+}
+
+// roleName returns the display name of a role.
+func (b *Bot) roleName(lang string, role config.Role) string {
+	if role == config.RoleNone {
+		return b.i18n.Text(lang, "role.none")
+	}
+	return b.i18n.Text(lang, "role."+string(role))
+}
+
+// deniedText returns the reply for a user lacking a permission.
+func (b *Bot) deniedText(userID int64) string {
+	if b.roleOf(userID) == config.RoleNone {
+		return b.t(userID, "error.not_authorized")
+	}
+	return b.t(userID, "error.role_denied")
+}
+
+// handleRole handles the /role command (admin only).
+func (b *Bot) handleRole(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_role", nil)
+
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
+	}
+
+	names := make([]string, len(config.AssignableRoles))
+	for i, r := range config.AssignableRoles {
+		names[i] = string(r)
+	}
+	lang := b.lang(userID)
+	usage := b.i18n.Text(lang, "usage.role", strings.Join(names, "|"))
+
+	args := strings.Fields(c.Text())[1:]
+	if len(args) > 2 {
+		return c.Send(usage)
+	}
+
+	targetID, rest, errText := b.resolveTarget(c, args)
+	if errText != "" {
+		if len(args) == 0 && !c.Message().IsReply() {
+			return c.Send(usage)
+		}
+		logger.WithUser(userID, username).Error("invalid user reference", "input", args)
+		return c.Send(errText)
+	}
+
+	// Without a role argument, show the current role
+	if len(rest) == 0 {
+		role := b.roleOf(targetID)
+		return c.Send(b.i18n.Text(lang, "role.current", targetID, b.roleName(lang, role)))
+	}
+
+	if len(rest) > 1 {
+		return c.Send(usage)
+	}
+
+	role, err := config.ParseRole(rest[0])
+	if err != nil {
+		return c.Send(b.i18n.Text(lang, "role.unknown", rest[0]))
+	}
+
+	if role == config.RoleAdmin {
+		return c.Send(b.i18n.Text(lang, "role.use_promote"))
+	}
+
+	if err := b.config.SetRole(targetID, role); err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("role change failed", "target", targetID)
+		return c.Send(b.errorText(userID, err))
+	}
+
+	logger.WithUser(userID, username).WithFields(map[string]interface{}{
+		"target": targetID,
+		"role":   role,
+	}).Info("role changed")
+	go b.refreshCommands(targetID)
+
+	return c.Send(b.i18n.Text(lang, "role.changed", targetID, b.roleName(lang, role)))
+}
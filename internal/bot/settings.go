@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"slices"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+// handleSettings handles the /settings command. Every authorized user can
+// choose a language; the upload options are shown to uploaders only.
+func (b *Bot) handleSettings(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_settings", nil)
+
+	if !b.can(userID, config.PermView) {
+		logger.WithUser(userID, username).Warn("unauthorized settings access")
+		return c.Send(b.deniedText(userID))
+	}
+
+	lang := b.lang(userID)
+	return c.Send(b.i18n.Text(lang, "settings.title"), b.settingsMarkup(lang, b.settings.Get(userID), b.can(userID, config.PermUpload)))
+}
+
+// handleSettingsChange toggles or cycles the setting named by key.
+func (b *Bot) handleSettingsChange(c telebot.Context, key string) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	if !b.can(userID, config.PermView) || (key != "language" && !b.can(userID, config.PermUpload)) {
+		return c.Edit(b.deniedText(userID))
+	}
+
+	settings, err := b.settings.Update(userID, func(s *storage.UserSettings) {
+		switch key {
+		case "auto":
+			s.AutoUpload = !s.AutoUpload
+		case "exif":
+			s.StripEXIF = !s.StripEXIF
+		case "variant":
+			s.Variant = next(append([]string{""}, b.config.Cloudflare.Variants...), s.Variant)
+		case "format":
+			s.ReplyFormat = string(next(replyFormats, parseReplyFormat(s.ReplyFormat)))
+		case "language":
+			s.Language = next(append([]string{""}, b.i18n.Languages()...), s.Language)
+		}
+	})
+	if err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("failed to save settings")
+		return c.Edit(b.t(userID, "settings.save_failed"))
+	}
+
+	logger.WithUser(userID, username).Debug("settings changed", "setting", key)
+
+	if key == "language" {
+		go b.refreshCommands(userID)
+	}
+
+	// Read the language after the change so switching it takes effect at once
+	lang := b.lang(userID)
+	return c.Edit(b.i18n.Text(lang, "settings.title"), b.settingsMarkup(lang, settings, b.can(userID, config.PermUpload)))
+}
+
+// settingsMarkup builds the settings menu showing the current values. The
+// upload options are included only when uploads is set.
+func (b *Bot) settingsMarkup(lang string, settings storage.UserSettings, uploads bool) *telebot.ReplyMarkup {
+	text := func(key string, args ...interface{}) string {
+		return b.i18n.Text(lang, key, args...)
+	}
+
+	variant := settings.Variant
+	if variant == "" {
+		variant = text("settings.variant_default")
+	}
+
+	language := text("settings.language_auto")
+	if settings.Language != "" {
+		language = b.i18n.Text(settings.Language, "language.name")
+	}
+
+	selector := &telebot.ReplyMarkup{}
+
+	var rows []telebot.Row
+	if uploads {
+		rows = append(rows,
+			selector.Row(selector.Data(text("settings.auto_upload", b.onOff(lang, settings.AutoUpload)), "settings", "auto")),
+			selector.Row(selector.Data(text("settings.strip_exif", b.onOff(lang, settings.StripEXIF)), "settings", "exif")),
+			selector.Row(selector.Data(text("settings.variant", variant), "settings", "variant")),
+			selector.Row(selector.Data(text("settings.reply_format", b.formatName(lang, parseReplyFormat(settings.ReplyFormat))), "settings", "format")),
+		)
+	}
+	rows = append(rows, selector.Row(selector.Data(text("settings.language", language), "settings", "language")))
+
+	selector.Inline(rows...)
+	return selector
+}
+
+// onOff formats a boolean setting.
+func (b *Bot) onOff(lang string, enabled bool) string {
+	if enabled {
+		return b.i18n.Text(lang, "settings.on")
+	}
+	return b.i18n.Text(lang, "settings.off")
+}
+
+// next returns the option after current, wrapping around. Unknown values
+// restart from the first option.
+func next[T comparable](options []T, current T) T {
+	i := slices.Index(options, current)
+	return options[(i+1)%len(options)]
+}
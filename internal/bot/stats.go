@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+// recordAttempt adds a finished job to the upload log. Jobs cancelled by
+// the user or interrupted by shutdown before uploading are not counted.
+func (b *Bot) recordAttempt(job *uploadJob, err error, elapsed time.Duration) {
+	record := storage.UploadRecord{
+		UserID:   job.userID,
+		Size:     job.size,
+		Format:   job.format,
+		Duration: elapsed.Milliseconds(),
+	}
+
+	if !job.uploaded {
+		cause := context.Cause(job.ctx)
+		if apperrors.Is(cause, apperrors.ErrUploadCanceled) || apperrors.Is(cause, apperrors.ErrShuttingDown) {
+			return
+		}
+		record.Error = errorType(err)
+	}
+
+	if err := b.uploadLog.Append(record); err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Error("failed to record upload attempt")
+	}
+}
+
+// errorType names the application error type of err for statistics.
+func errorType(err error) string {
+	for _, m := range errorMessages {
+		if apperrors.Is(err, m.err) {
+			return m.err.Error()
+		}
+	}
+	return "other"
+}
+
+// uploadStats summarizes upload log records.
+type uploadStats struct {
+	uploads  int
+	bytes    int64
+	failures int
+	latency  time.Duration // total for successful uploads
+	users    map[int64]int
+	formats  map[string]int
+	errors   map[string]int
+}
+
+// summarize aggregates records.
+func summarize(records []storage.UploadRecord) uploadStats {
+	s := uploadStats{
+		users:   make(map[int64]int),
+		formats: make(map[string]int),
+		errors:  make(map[string]int),
+	}
+
+	for _, r := range records {
+		if r.Error != "" {
+			s.failures++
+			s.errors[r.Error]++
+			continue
+		}
+
+		s.uploads++
+		s.bytes += r.Size
+		s.latency += time.Duration(r.Duration) * time.Millisecond
+		s.users[r.UserID]++
+		s.formats[strings.ToUpper(r.Format)]++
+	}
+
+	return s
+}
+
+// counted is a name with a count, for ranked lists.
+type counted[K comparable] struct {
+	key   K
+	count int
+}
+
+// ranked returns the entries of m by descending count.
+func ranked[K cmp.Ordered](m map[K]int) []counted[K] {
+	entries := make([]counted[K], 0, len(m))
+	for k, n := range m {
+		entries = append(entries, counted[K]{k, n})
+	}
+	slices.SortFunc(entries, func(a, b counted[K]) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.key, b.key)
+	})
+	return entries
+}
+
+// percent returns n as a percentage of total.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// handleStats handles the /stats command (admin only).
+func (b *Bot) handleStats(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_stats", nil)
+
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
+	}
+
+	lang := b.lang(userID)
+	now := time.Now()
+	records := b.uploadLog.Since(now.Add(-constants.StatsRetention))
+
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "stats.title"))
+
+	periods := []struct {
+		key    string
+		window time.Duration
+	}{
+		{"stats.day", 24 * time.Hour},
+		{"stats.week", 7 * 24 * time.Hour},
+		{"stats.month", constants.StatsRetention},
+	}
+	for _, p := range periods {
+		since := now.Add(-p.window)
+		i, _ := slices.BinarySearchFunc(records, since, func(r storage.UploadRecord, t time.Time) int {
+			return r.Time.Compare(t)
+		})
+		s := summarize(records[i:])
+		sb.WriteString(b.i18n.Text(lang, p.key, s.uploads, float64(s.bytes)/bytesPerMB))
+	}
+
+	s := summarize(records)
+	if s.uploads+s.failures == 0 {
+		sb.WriteString(b.i18n.Text(lang, "stats.empty"))
+		return c.Send(sb.String())
+	}
+
+	if s.uploads > 0 {
+		sb.WriteString(b.i18n.Text(lang, "stats.top_uploaders"))
+		for i, e := range ranked(s.users) {
+			if i == constants.StatsTopUploaders {
+				break
+			}
+			info, _ := b.users.Get(e.key)
+			name := displayName(&telebot.User{ID: e.key, Username: info.Username, FirstName: info.FirstName, LastName: info.LastName})
+			sb.WriteString(b.i18n.Text(lang, "stats.uploader", i+1, name, e.count))
+		}
+
+		sb.WriteString(b.i18n.Text(lang, "stats.formats"))
+		for _, e := range ranked(s.formats) {
+			sb.WriteString(b.i18n.Text(lang, "stats.entry", e.key, e.count, percent(e.count, s.uploads)))
+		}
+
+		sb.WriteString(b.i18n.Text(lang, "stats.latency", (s.latency / time.Duration(s.uploads)).Seconds()))
+	}
+
+	total := s.uploads + s.failures
+	sb.WriteString(b.i18n.Text(lang, "stats.failures", percent(s.failures, total), s.failures, total))
+	for _, e := range ranked(s.errors) {
+		sb.WriteString(b.i18n.Text(lang, "stats.entry", e.key, e.count, percent(e.count, total)))
+	}
+
+	return c.Send(sb.String())
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+
+	"gopkg.in/telebot.v3"
+)
+
+// throttle waits for both the global and the per-chat Telegram limiters.
+func (b *Bot) throttle(ctx context.Context, chatID int64) error {
+	if err := b.tgLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	return b.chatLimiter.Wait(ctx, chatID)
+}
+
+// sendMessage sends a message once the Telegram rate limiters allow it.
+func (b *Bot) sendMessage(ctx context.Context, chat *telebot.Chat, what interface{}, opts ...interface{}) (*telebot.Message, error) {
+	if err := b.throttle(ctx, chat.ID); err != nil {
+		return nil, err
+	}
+
+	return b.telebot.Send(chat, what, opts...)
+}
+
+// editMessage edits a message once the Telegram rate limiters allow it.
+func (b *Bot) editMessage(ctx context.Context, msg *telebot.Message, what interface{}, opts ...interface{}) (*telebot.Message, error) {
+	if err := b.throttle(ctx, msg.Chat.ID); err != nil {
+		return nil, err
+	}
+
+	return b.telebot.Edit(msg, what, opts...)
+}
+
+// editMessageIfIdle edits a message only if the chat's limiter has a token
+// to spare right now, so best-effort updates never delay other edits in
+// the same chat. It reports whether the edit was attempted.
+func (b *Bot) editMessageIfIdle(ctx context.Context, msg *telebot.Message, what interface{}, opts ...interface{}) bool {
+	if !b.chatLimiter.Allow(msg.Chat.ID) {
+		return false
+	}
+
+	if err := b.tgLimiter.Wait(ctx); err != nil {
+		return false
+	}
+
+	b.telebot.Edit(msg, what, opts...)
+	return true
+}
+
+// scheduleQueueRefresh refreshes queue positions in the background. Calls
+// made while a refresh is running are coalesced into one more pass, so
+// workers never block on rate-limited edits.
+func (b *Bot) scheduleQueueRefresh() {
+	b.refreshMu.Lock()
+	if b.refreshing {
+		b.refreshPending = true
+		b.refreshMu.Unlock()
+		return
+	}
+	b.refreshing = true
+	b.refreshMu.Unlock()
+
+	go func() {
+		for {
+			b.refreshQueuePositions()
+
+			b.refreshMu.Lock()
+			if !b.refreshPending {
+				b.refreshing = false
+				b.refreshMu.Unlock()
+				return
+			}
+			b.refreshPending = false
+			b.refreshMu.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/cloudflare"
+	"telegram-cf-bot/internal/constants"
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/validator"
+)
+
+// processImageUpload handles the complete image upload flow for a queued job.
+func (b *Bot) processImageUpload(job *uploadJob) error {
+	userID := job.userID
+	ctx := job.ctx
+	settings := b.settings.Get(userID)
+	lang := b.lang(userID)
+	text := func(key string, args ...interface{}) string {
+		return b.i18n.Text(lang, key, args...)
+	}
+
+	// Download file from Telegram
+	b.setStatus(ctx, job, text("upload.downloading"), b.cancelMarkup(job))
+
+	infoCtx, cancel := context.WithTimeout(ctx, constants.ContextTimeout)
+	file, err := b.fileByID(infoCtx, job.fileID)
+	cancel()
+	if err != nil {
+		return b.failJob(job, "", apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to get file info", err))
+	}
+
+	// Enforce quota before spending bandwidth on the download; the
+	// reservation keeps concurrent jobs from slipping past the same limit
+	if reason := b.reserveQuota(userID, file.FileSize); reason != "" {
+		return b.failJob(job, text("upload.quota_exceeded", reason),
+			apperrors.New(apperrors.ErrQuotaExceeded, fmt.Sprintf("user %d exceeded upload quota", userID)))
+	}
+	defer b.releaseQuota(userID, file.FileSize)
+
+	downloadCtx, cancel := context.WithTimeout(ctx, constants.DownloadTimeout)
+	defer cancel()
+
+	imageBytes, err := b.downloadFile(downloadCtx, &file)
+	if err != nil {
+		return b.failJob(job, "", err)
+	}
+	job.size = int64(len(imageBytes))
+
+	// Validate image
+	b.setStatus(ctx, job, text("upload.validating"), b.cancelMarkup(job))
+
+	validationResult, err := validator.Validate(imageBytes)
+	if err != nil {
+		return b.failJob(job, "", err)
+	}
+	job.format = validationResult.Format
+
+	if settings.StripEXIF {
+		imageBytes = validator.StripEXIF(imageBytes)
+		for _, key := range []string{"camera_make", "camera_model", "date_time"} {
+			delete(validationResult.Metadata, key)
+		}
+	}
+
+	// Upload to Cloudflare
+	b.setStatus(ctx, job, text("upload.uploading"), b.cancelMarkup(job))
+
+	b.markSending(job)
+
+	uploadCtx, cancel := context.WithTimeout(ctx, constants.UploadTimeout)
+	defer cancel()
+
+	uploadResp, err := b.cfClient.Upload(uploadCtx, imageBytes, userID, validationResult.Metadata)
+	if err != nil {
+		return b.failJob(job, "", err)
+	}
+
+	// Get image URL
+	imageURL, err := cloudflare.GetVariantURL(uploadResp, settings.Variant)
+	if err != nil {
+		return b.failJob(job, "", err)
+	}
+
+	job.size = int64(len(imageBytes))
+	job.uploaded = true
+	b.recordUsage(userID, job.size)
+	b.users.RecordUpload(userID)
+	b.recordImage(job, uploadResp, imageURL)
+
+	// Send success message
+	format := parseReplyFormat(settings.ReplyFormat)
+	err = b.setStatus(context.Background(), job, b.successText(lang, format, imageURL), telebot.ModeHTML, b.replyFormatMarkup(lang, format, userID))
+
+	b.publishUpload(job, imageURL)
+
+	return err
+}
+
+// fileByID fetches file info from Telegram, giving up once ctx is done.
+func (b *Bot) fileByID(ctx context.Context, fileID string) (telebot.File, error) {
+	type result struct {
+		file telebot.File
+		err  error
+	}
+
+	// telebot does not accept a context, so race the call against ctx
+	ch := make(chan result, 1)
+	go func() {
+		file, err := b.telebot.FileByID(fileID)
+		ch <- result{file, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.file, r.err
+	case <-ctx.Done():
+		return telebot.File{}, ctx.Err()
+	}
+}
+
+// downloadFile downloads file content from Telegram.
+func (b *Bot) downloadFile(ctx context.Context, file *telebot.File) ([]byte, error) {
+	fileURL := fmt.Sprintf("%s/file/bot%s/%s", b.telebot.URL, b.telebot.Token, file.FilePath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to create request", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to download file", err)
+	}
+	defer resp.Body.Close()
+
+	imageBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrDownloadFailed, "failed to read file", err)
+	}
+
+	return imageBytes, nil
+}
+
+// setStatus updates the job's status message, sending a new one if none
+// exists. Progress updates pass the job context so they are skipped once
+// the job is cancelled; final results pass a background context.
+func (b *Bot) setStatus(ctx context.Context, job *uploadJob, text string, opts ...interface{}) error {
+	if job.status != nil {
+		_, err := b.editMessage(ctx, job.status, text, opts...)
+		return err
+	}
+
+	msg, err := b.sendMessage(ctx, job.chat, text, opts...)
+	if err != nil {
+		logger.WithUser(job.userID, job.username).WithError(err).Error("failed to send status message")
+		return err
+	}
+
+	job.status = msg
+	return nil
+}
+
+// failJob reports a failed job to the user and returns err. An empty text
+// is derived from err; cancellation and timeouts replace any text with a
+// clearer reason.
+func (b *Bot) failJob(job *uploadJob, text string, err error) error {
+	switch cause := context.Cause(job.ctx); {
+	case apperrors.Is(cause, apperrors.ErrUploadCanceled):
+		text = b.t(job.userID, "upload.canceled")
+	case apperrors.Is(cause, apperrors.ErrShuttingDown) && job.sending:
+		text = b.t(job.userID, "upload.interrupted_uploading")
+	case apperrors.Is(cause, apperrors.ErrShuttingDown):
+		text = b.t(job.userID, "upload.interrupted")
+	case apperrors.Is(err, context.DeadlineExceeded):
+		text = b.t(job.userID, "upload.timeout")
+	case text == "":
+		text = b.errorText(job.userID, err)
+	}
+
+	b.setStatus(context.Background(), job, text)
+	return err
+}
+
+// cancelMarkup builds the inline keyboard that lets a user abort a job.
+func (b *Bot) cancelMarkup(job *uploadJob) *telebot.ReplyMarkup {
+	selector := &telebot.ReplyMarkup{}
+	selector.Inline(selector.Row(selector.Data(b.t(job.userID, "button.cancel"), "cancel_job", job.id)))
+	return selector
+}
@@ -0,0 +1,246 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v3"
+
+	"telegram-cf-bot/internal/config"
+	"telegram-cf-bot/internal/constants"
+	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/storage"
+)
+
+// trackUser is middleware recording the sender of every update.
+func (b *Bot) trackUser(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		if user := c.Sender(); user != nil && !user.IsBot {
+			isNew := b.users.Touch(storage.UserInfo{
+				ID:           user.ID,
+				Username:     user.Username,
+				FirstName:    user.FirstName,
+				LastName:     user.LastName,
+				LanguageCode: user.LanguageCode,
+			}, time.Now())
+
+			// Group members gain access without any admin action, so give
+			// them their menu the first time they show up
+			if isNew && len(b.config.GroupAuth.Chats) > 0 {
+				go b.refreshGroupCommands(user.ID)
+			}
+		}
+		return next(c)
+	}
+}
+
+// flushUsers periodically persists the user store until the bot stops.
+func (b *Bot) flushUsers() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(constants.UserFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.users.Flush(); err != nil {
+				logger.WithError(err).Error("failed to flush user store")
+			}
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// sweepExpiredUsers periodically removes lapsed temporary authorizations
+// until the bot stops.
+func (b *Bot) sweepExpiredUsers() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(constants.ExpirySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.removeExpiredUsers()
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// removeExpiredUsers revokes lapsed authorizations and tells admins and
+// the affected users.
+func (b *Bot) removeExpiredUsers() {
+	expired, err := b.config.RemoveExpiredUsers(time.Now())
+	if err != nil {
+		logger.WithError(err).Error("failed to remove expired users")
+	}
+
+	for _, userID := range expired {
+		info, _ := b.users.Get(userID)
+		name := displayName(&telebot.User{ID: userID, Username: info.Username, FirstName: info.FirstName, LastName: info.LastName})
+
+		logger.WithUser(userID, info.Username).Info("temporary authorization expired")
+		go b.refreshCommands(userID)
+
+		b.notifyAdmins(nil, "users.admin_expired", name, userID)
+		if _, err := b.sendMessage(b.ctx, &telebot.Chat{ID: userID}, b.t(userID, "users.expired")); err != nil {
+			logger.WithUser(userID, info.Username).WithError(err).Debug("failed to notify expired user")
+		}
+	}
+}
+
+// handleUsers handles the /users command (admin only).
+func (b *Bot) handleUsers(c telebot.Context) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	logger.LogUserAction(userID, username, "command_users", nil)
+
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted admin command")
+		return c.Send(b.t(userID, "error.admin_only"))
+	}
+
+	text, markup := b.renderUsersPage(b.lang(userID), 0)
+	return c.Send(text, markup)
+}
+
+// handleUsersPage switches the /users list to another page.
+func (b *Bot) handleUsersPage(c telebot.Context, payload string) error {
+	if !b.can(c.Sender().ID, config.PermManageUsers) {
+		return nil
+	}
+
+	page, _ := strconv.Atoi(payload)
+	text, markup := b.renderUsersPage(b.lang(c.Sender().ID), page)
+	return c.Edit(text, markup)
+}
+
+// handleRevokeUser revokes a user's authorization from the /users list.
+func (b *Bot) handleRevokeUser(c telebot.Context, payload string) error {
+	userID := c.Sender().ID
+	username := c.Sender().Username
+
+	if !b.can(userID, config.PermManageUsers) {
+		logger.WithUser(userID, username).Warn("non-admin attempted to revoke user")
+		return nil
+	}
+
+	idText, pageText, _ := strings.Cut(payload, "|")
+	targetID, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		logger.WithUser(userID, username).Error("invalid revoke payload", "payload", payload)
+		return c.Edit(b.t(userID, "error.unknown_action"))
+	}
+	page, _ := strconv.Atoi(pageText)
+
+	lang := b.lang(userID)
+	notice := b.i18n.Text(lang, "users.revoked", targetID)
+	if err := b.config.RemoveAuthorizedUser(targetID); err != nil {
+		logger.WithUser(userID, username).WithError(err).Error("unauth failed", "target", targetID)
+		notice = b.errorText(userID, err)
+	} else {
+		logger.WithUser(userID, username).Info("unauth successful", "target", targetID)
+		go b.refreshCommands(targetID)
+	}
+
+	text, markup := b.renderUsersPage(lang, page)
+	return c.Edit(notice+"\n\n"+text, markup)
+}
+
+// renderUsersPage formats one page of authorized users with revoke buttons.
+func (b *Bot) renderUsersPage(lang string, page int) (string, *telebot.ReplyMarkup) {
+	ids := b.config.UserIDs()
+	selector := &telebot.ReplyMarkup{}
+
+	if len(ids) == 0 {
+		return b.i18n.Text(lang, "users.empty"), selector
+	}
+
+	pages := (len(ids) + constants.UsersPageSize - 1) / constants.UsersPageSize
+	page = min(max(page, 0), pages-1)
+	start := page * constants.UsersPageSize
+	end := min(start+constants.UsersPageSize, len(ids))
+
+	var sb strings.Builder
+	sb.WriteString(b.i18n.Text(lang, "users.header", len(ids), page+1, pages))
+
+	var rows []telebot.Row
+	for i, id := range ids[start:end] {
+		info, known := b.users.Get(id)
+		name := displayName(&telebot.User{ID: id, Username: info.Username, FirstName: info.FirstName, LastName: info.LastName})
+		role := b.config.RoleOf(id)
+
+		lastSeen := b.i18n.Text(lang, "users.never")
+		if known && !info.LastSeen.IsZero() {
+			lastSeen = formatTime(info.LastSeen)
+		}
+
+		sb.WriteString(b.i18n.Text(lang, "users.entry",
+			start+i+1, name, id, b.roleName(lang, role), lastSeen, info.Uploads))
+		if expiresAt, ok := b.config.ExpiryOf(id); ok {
+			sb.WriteString(b.i18n.Text(lang, "users.expires", formatTime(expiresAt)))
+		}
+
+		if role != config.RoleAdmin {
+			rows = append(rows, selector.Row(selector.Data(
+				b.i18n.Text(lang, "button.revoke", name), "revoke_user", strconv.FormatInt(id, 10), strconv.Itoa(page))))
+		}
+	}
+
+	var nav []telebot.Btn
+	if page > 0 {
+		nav = append(nav, selector.Data(b.i18n.Text(lang, "button.prev"), "users_page", strconv.Itoa(page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, selector.Data(b.i18n.Text(lang, "button.next"), "users_page", strconv.Itoa(page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, selector.Row(nav...))
+	}
+
+	selector.Inline(rows...)
+	return sb.String(), selector
+}
+
+// resolveTarget identifies the user a management command refers to: a
+// numeric ID, an @username the bot has seen before, or the author of the
+// replied-to message (the original author if it was forwarded). It returns
+// the remaining arguments, or a user-facing error text.
+func (b *Bot) resolveTarget(c telebot.Context, args []string) (int64, []string, string) {
+	senderID := c.Sender().ID
+
+	if len(args) > 0 {
+		arg := args[0]
+
+		if strings.HasPrefix(arg, "@") {
+			info, ok := b.users.FindByUsername(arg)
+			if !ok {
+				return 0, nil, b.t(senderID, "target.unknown_username", arg)
+			}
+			return info.ID, args[1:], ""
+		}
+
+		if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			return id, args[1:], ""
+		}
+	}
+
+	if reply := c.Message().ReplyTo; reply != nil {
+		switch {
+		case reply.OriginalSender != nil:
+			return reply.OriginalSender.ID, args, ""
+		case reply.IsForwarded() || reply.OriginalSenderName != "":
+			return 0, nil, b.t(senderID, "target.hidden_forward")
+		case reply.Sender != nil && !reply.Sender.IsBot:
+			return reply.Sender.ID, args, ""
+		}
+	}
+
+	return 0, nil, b.t(senderID, "target.invalid")
+}
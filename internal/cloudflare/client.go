@@ -3,25 +3,28 @@ package cloudflare
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"mime/multipart"
 	"net/http"
+	"path"
 	"time"
 
 	"telegram-cf-bot/internal/config"
 	"telegram-cf-bot/internal/constants"
 	apperrors "telegram-cf-bot/internal/errors"
 	"telegram-cf-bot/internal/logger"
+	"telegram-cf-bot/internal/ratelimit"
 )
 
 // Client provides Cloudflare API operations.
 type Client struct {
 	config     *config.Config
 	httpClient *http.Client
+	limiter    *ratelimit.Limiter
 }
 
 // UploadResponse represents Cloudflare API upload response.
@@ -38,6 +41,35 @@ type UploadResponse struct {
 	} `json:"errors"`
 }
 
+// ImageStats holds the number of images stored in the account and how
+// many it may store.
+type ImageStats struct {
+	Current int64
+	Allowed int64
+}
+
+// statsResponse represents Cloudflare API images stats response.
+type statsResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		Count struct {
+			Current int64 `json:"current"`
+			Allowed int64 `json:"allowed"`
+		} `json:"count"`
+	} `json:"result"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// deleteResponse represents Cloudflare API delete image response.
+type deleteResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
 // NewClient creates a new Cloudflare API client.
 func NewClient(cfg *config.Config) *Client {
 	return &Client{
@@ -45,11 +77,12 @@ func NewClient(cfg *config.Config) *Client {
 		httpClient: &http.Client{
 			Timeout: constants.HTTPClientTimeout,
 		},
+		limiter: ratelimit.New(cfg.RateLimit.Cloudflare.Rate, cfg.RateLimit.Cloudflare.Burst),
 	}
 }
 
-// Upload uploads an image to Cloudflare Images.
-func (c *Client) Upload(imageBytes []byte, userID int64, metadata map[string]interface{}) (*UploadResponse, error) {
+// Upload uploads an image to Cloudflare Images. The request is aborted when ctx is done.
+func (c *Client) Upload(ctx context.Context, imageBytes []byte, userID int64, metadata map[string]interface{}) (*UploadResponse, error) {
 	start := time.Now()
 	filename := generateFilename(userID)
 
@@ -66,40 +99,21 @@ func (c *Client) Upload(imageBytes []byte, userID int64, metadata map[string]int
 		return nil, err
 	}
 
-	// Create request
 	url := fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/images/v1",
 		c.config.Cloudflare.AccountID)
 
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "failed to create request", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.config.Cloudflare.APIToken)
-	req.Header.Set("Content-Type", contentType)
-
-	// Send request
-	resp, err := c.httpClient.Do(req)
+	// Send request, retrying transient failures with the same body
+	resp, err := c.do(ctx, "POST", url, body.Bytes(), contentType)
 	duration := time.Since(start).Milliseconds()
-
-	if err != nil {
-		logger.LogAPICall("cloudflare", "POST", url, 0, duration, err)
-		return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "request failed", err)
-	}
-	defer resp.Body.Close()
-
-	logger.LogAPICall("cloudflare", "POST", url, resp.StatusCode, duration, nil)
-
-	// Read response
-	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "failed to read response", err)
+		return nil, err
 	}
 
 	// Parse response
 	var result UploadResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "failed to parse response", err)
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrUploadFailed,
+			fmt.Sprintf("failed to parse response (status %d)", resp.StatusCode), err)
 	}
 
 	// Check success
@@ -124,6 +138,69 @@ func (c *Client) Upload(imageBytes []byte, userID int64, metadata map[string]int
 	return &result, nil
 }
 
+// GetStats fetches the account's image count and limit.
+func (c *Client) GetStats(ctx context.Context) (*ImageStats, error) {
+	url := fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/images/v1/stats",
+		c.config.Cloudflare.AccountID)
+
+	resp, err := c.do(ctx, "GET", url, nil, "")
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrCloudflareAPI, "failed to fetch image stats", err)
+	}
+
+	var result statsResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrCloudflareAPI,
+			fmt.Sprintf("failed to parse stats response (status %d)", resp.StatusCode), err)
+	}
+
+	if !result.Success {
+		var msgs []string
+		for _, e := range result.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return nil, apperrors.New(apperrors.ErrCloudflareAPI, fmt.Sprintf("API errors: %v", msgs))
+	}
+
+	return &ImageStats{
+		Current: result.Result.Count.Current,
+		Allowed: result.Result.Count.Allowed,
+	}, nil
+}
+
+// Delete removes an image from Cloudflare Images. An image that no longer
+// exists counts as deleted.
+func (c *Client) Delete(ctx context.Context, imageID string) error {
+	url := fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/images/v1/%s",
+		c.config.Cloudflare.AccountID, imageID)
+
+	resp, err := c.do(ctx, "DELETE", url, nil, "")
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrDeleteFailed, "failed to delete image", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	var result deleteResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return apperrors.Wrap(apperrors.ErrDeleteFailed,
+			fmt.Sprintf("failed to parse delete response (status %d)", resp.StatusCode), err)
+	}
+
+	if !result.Success {
+		var msgs []string
+		for _, e := range result.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return apperrors.New(apperrors.ErrDeleteFailed, fmt.Sprintf("API errors: %v", msgs))
+	}
+
+	logger.WithFields(map[string]interface{}{"image_id": imageID}).Info("image deleted")
+	return nil
+}
+
 // GetImageURL extracts the image URL from upload response.
 func GetImageURL(resp *UploadResponse) (string, error) {
 	if resp == nil || !resp.Success {
@@ -137,6 +214,23 @@ func GetImageURL(resp *UploadResponse) (string, error) {
 	return resp.Result.Variants[0], nil
 }
 
+// GetVariantURL returns the URL of the named variant, falling back to the
+// first variant when variant is empty or not part of the response.
+func GetVariantURL(resp *UploadResponse, variant string) (string, error) {
+	imageURL, err := GetImageURL(resp)
+	if err != nil || variant == "" {
+		return imageURL, err
+	}
+
+	for _, u := range resp.Result.Variants {
+		if path.Base(u) == variant {
+			return u, nil
+		}
+	}
+
+	return imageURL, nil
+}
+
 // buildMultipartBody creates multipart form data for upload.
 func (c *Client) buildMultipartBody(imageBytes []byte, filename string, metadata map[string]interface{}) (*bytes.Buffer, string, error) {
 	var body bytes.Buffer
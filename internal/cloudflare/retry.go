@@ -0,0 +1,148 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	apperrors "telegram-cf-bot/internal/errors"
+	"telegram-cf-bot/internal/logger"
+)
+
+// apiResponse holds the raw result of a completed API request.
+type apiResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// do sends a request, retrying 429, 5xx and network errors according to
+// the configured retry policy. The body is replayed on every attempt.
+func (c *Client) do(ctx context.Context, method, url string, body []byte, contentType string) (*apiResponse, error) {
+	policy := c.config.Cloudflare.Retry
+
+	for attempt := 1; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		// Smooth bursts instead of tripping Cloudflare's API rate limit
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "request aborted while rate limited", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "failed to create request", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+c.config.Cloudflare.APIToken)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		duration := time.Since(start).Milliseconds()
+
+		var wait time.Duration
+		if err != nil {
+			logger.LogAPICall("cloudflare", method, url, 0, duration, err)
+
+			if ctx.Err() != nil || attempt >= policy.MaxAttempts {
+				return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "request failed", err)
+			}
+			wait = c.backoff(attempt)
+		} else {
+			respBody, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if !isRetryableStatus(resp.StatusCode) || attempt >= policy.MaxAttempts {
+				logger.LogAPICall("cloudflare", method, url, resp.StatusCode, duration, readErr)
+				if readErr != nil {
+					return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "failed to read response", readErr)
+				}
+				return &apiResponse{StatusCode: resp.StatusCode, Body: respBody}, nil
+			}
+
+			logger.LogAPICall("cloudflare", method, url, resp.StatusCode, duration,
+				fmt.Errorf("retryable status %d", resp.StatusCode))
+
+			wait = retryAfter(resp.Header)
+			if wait <= 0 {
+				wait = c.backoff(attempt)
+			}
+
+			// A Retry-After beyond the backoff cap or the caller's deadline
+			// cannot succeed in time, so report the response now instead
+			if wait > policy.MaxBackoff || !fitsDeadline(ctx, wait) {
+				if readErr != nil {
+					return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "failed to read response", readErr)
+				}
+				return &apiResponse{StatusCode: resp.StatusCode, Body: respBody}, nil
+			}
+		}
+
+		logger.WithFields(logger.Fields{
+			"attempt":      attempt,
+			"max_attempts": policy.MaxAttempts,
+			"wait_ms":      wait.Milliseconds(),
+		}).Warn("retrying cloudflare request")
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, apperrors.Wrap(apperrors.ErrUploadFailed, "request aborted while waiting to retry", ctx.Err())
+		}
+	}
+}
+
+// backoff returns the exponential delay before the next attempt with
+// jitter applied, so concurrent workers do not retry in lockstep.
+func (c *Client) backoff(attempt int) time.Duration {
+	policy := c.config.Cloudflare.Retry
+
+	delay := policy.InitialBackoff << (attempt - 1)
+	if delay <= 0 || delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// fitsDeadline reports whether waiting for wait leaves ctx time to spare.
+func fitsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > wait
+}
+
+// isRetryableStatus reports whether a response status is worth retrying.
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+
+	return 0
+}
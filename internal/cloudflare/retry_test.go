@@ -0,0 +1,97 @@
+package cloudflare
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"telegram-cf-bot/internal/config"
+)
+
+func TestBackoff(t *testing.T) {
+	client := &Client{config: &config.Config{Cloudflare: config.CloudflareConfig{
+		Retry: config.RetryConfig{
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     10 * time.Second,
+		},
+	}}}
+
+	tests := []struct {
+		name    string
+		attempt int
+		delay   time.Duration
+	}{
+		{"first attempt", 1, time.Second},
+		{"doubles", 2, 2 * time.Second},
+		{"doubles again", 3, 4 * time.Second},
+		{"capped", 5, 10 * time.Second},
+		{"large shift capped", 40, 10 * time.Second},
+		{"overflow capped", 80, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				got := client.backoff(tt.attempt)
+				if got < tt.delay/2 || got > tt.delay {
+					t.Fatalf("backoff(%d) = %v, want within [%v, %v]", tt.attempt, got, tt.delay/2, tt.delay)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"missing", "", 0, 0},
+		{"seconds", "3", 3 * time.Second, 3 * time.Second},
+		{"zero seconds", "0", 0, 0},
+		{"http date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+		{"invalid", "soon", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+
+			got := retryAfter(header)
+			if got < tt.min || got > tt.max {
+				t.Errorf("retryAfter(%q) = %v, want within [%v, %v]", tt.value, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestFitsDeadline(t *testing.T) {
+	short, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		wait time.Duration
+		want bool
+	}{
+		{"no deadline", context.Background(), time.Hour, true},
+		{"within deadline", short, 10 * time.Millisecond, true},
+		{"beyond deadline", short, time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitsDeadline(tt.ctx, tt.wait); got != tt.want {
+				t.Errorf("fitsDeadline(%v) = %v, want %v", tt.wait, got, tt.want)
+			}
+		})
+	}
+}
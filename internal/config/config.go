@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -14,12 +17,24 @@ import (
 
 // Config holds all application configuration.
 type Config struct {
-	Telegram        TelegramConfig   `yaml:"telegram"`
-	Cloudflare      CloudflareConfig `yaml:"cloudflare"`
-	AuthorizedUsers []int64          `yaml:"authorized_users"`
-	AdminID         int64            `yaml:"admin_id"`
-	Logging         LoggingConfig    `yaml:"logging"`
-	configPath      string           `yaml:"-"`
+	Telegram        TelegramConfig      `yaml:"telegram"`
+	Cloudflare      CloudflareConfig    `yaml:"cloudflare"`
+	AuthorizedUsers []int64             `yaml:"authorized_users"`
+	Roles           map[int64]Role      `yaml:"roles,omitempty"`
+	AuthExpiry      map[int64]time.Time `yaml:"auth_expiry,omitempty"`
+	AuthorizedChats []int64             `yaml:"authorized_chats,omitempty"`
+	Admins          []int64             `yaml:"admins"`
+	AdminID         int64               `yaml:"admin_id,omitempty"` // Deprecated: merged into Admins on load
+	Logging         LoggingConfig       `yaml:"logging"`
+	Upload          UploadConfig        `yaml:"upload"`
+	RateLimit       RateLimitConfig     `yaml:"rate_limit"`
+	Storage         StorageConfig       `yaml:"storage"`
+	Quota           QuotaConfig         `yaml:"quota"`
+	GroupAuth       GroupAuthConfig     `yaml:"group_auth"`
+	Publish         PublishConfig       `yaml:"publish"`
+	Language        LanguageConfig      `yaml:"language"`
+	configPath      string              `yaml:"-"`
+	mu              sync.RWMutex
 }
 
 // TelegramConfig holds Telegram bot configuration.
@@ -29,8 +44,24 @@ type TelegramConfig struct {
 
 // CloudflareConfig holds Cloudflare API configuration.
 type CloudflareConfig struct {
-	AccountID string `yaml:"account_id"`
-	APIToken  string `yaml:"api_token"`
+	AccountID  string           `yaml:"account_id"`
+	APIToken   string           `yaml:"api_token"`
+	Retry      RetryConfig      `yaml:"retry"`
+	Variants   []string         `yaml:"variants,omitempty"` // variants users can pick in /settings
+	UsageAlert UsageAlertConfig `yaml:"usage_alert"`
+}
+
+// UsageAlertConfig controls warnings about the account's stored image count.
+type UsageAlertConfig struct {
+	Thresholds    []int         `yaml:"thresholds"` // percent of allowed images; an empty list disables warnings
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// RetryConfig holds retry policy for transient Cloudflare API failures.
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
 }
 
 // LoggingConfig holds logging configuration.
@@ -40,6 +71,66 @@ type LoggingConfig struct {
 	FilePath string `yaml:"file_path"`
 }
 
+// UploadConfig holds upload queue configuration.
+type UploadConfig struct {
+	Workers    int           `yaml:"workers"`
+	QueueSize  int           `yaml:"queue_size"`
+	PendingTTL time.Duration `yaml:"pending_ttl"` // how long photo confirmations stay valid
+}
+
+// RateLimitConfig holds client-side rate limits for outgoing API calls.
+type RateLimitConfig struct {
+	Cloudflare      LimitConfig `yaml:"cloudflare"`
+	TelegramGlobal  LimitConfig `yaml:"telegram_global"`
+	TelegramPerChat LimitConfig `yaml:"telegram_per_chat"`
+}
+
+// LimitConfig describes a token bucket: Rate tokens per second up to Burst.
+type LimitConfig struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// GroupAuthConfig authorizes members of Telegram group chats.
+type GroupAuthConfig struct {
+	Chats    []int64       `yaml:"chats"`
+	Role     Role          `yaml:"role"`
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// PublishConfig holds the channels successful uploads are posted to.
+type PublishConfig struct {
+	Channel int64           `yaml:"channel"`         // 0 disables publishing
+	Users   map[int64]int64 `yaml:"users,omitempty"` // per-user channel, 0 disables
+}
+
+// LanguageConfig selects the message catalogs.
+type LanguageConfig struct {
+	Default string `yaml:"default"`       // used when a user's language is unsupported
+	Dir     string `yaml:"dir,omitempty"` // extra *.yaml catalogs that add or override languages
+}
+
+// StorageConfig holds the location of persistent bot state.
+type StorageConfig struct {
+	DataDir string `yaml:"data_dir"`
+}
+
+// QuotaConfig holds global upload limits and per-user overrides.
+type QuotaConfig struct {
+	QuotaLimits `yaml:",inline"`
+	Users       map[int64]QuotaLimits `yaml:"users,omitempty"`
+}
+
+// QuotaLimits caps uploads per day and month. In the global limits 0
+// means unlimited; in a per-user override 0 inherits the global value
+// and a negative value means unlimited.
+type QuotaLimits struct {
+	DailyCount   int   `yaml:"daily_count"`
+	DailyMB      int64 `yaml:"daily_mb"`
+	MonthlyCount int   `yaml:"monthly_count"`
+	MonthlyMB    int64 `yaml:"monthly_mb"`
+}
+
 // Load loads configuration from file with validation.
 func Load(configPath string) (*Config, error) {
 	cfg := &Config{}
@@ -63,6 +154,14 @@ func Load(configPath string) (*Config, error) {
 
 	cfg.configPath = configPath
 
+	// Migrate the legacy single admin into the admins list
+	if cfg.AdminID != 0 {
+		if !slices.Contains(cfg.Admins, cfg.AdminID) {
+			cfg.Admins = append(cfg.Admins, cfg.AdminID)
+		}
+		cfg.AdminID = 0
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -74,6 +173,49 @@ func Load(configPath string) (*Config, error) {
 	if cfg.Logging.FilePath == "" {
 		cfg.Logging.FilePath = constants.DefaultLogFilePath
 	}
+	if cfg.Cloudflare.Retry.MaxAttempts <= 0 {
+		cfg.Cloudflare.Retry.MaxAttempts = constants.DefaultRetryAttempts
+	}
+	if cfg.Cloudflare.Retry.InitialBackoff <= 0 {
+		cfg.Cloudflare.Retry.InitialBackoff = constants.DefaultRetryInitialBackoff
+	}
+	if cfg.Cloudflare.Retry.MaxBackoff <= 0 {
+		cfg.Cloudflare.Retry.MaxBackoff = constants.DefaultRetryMaxBackoff
+	}
+	if len(cfg.Cloudflare.Variants) == 0 {
+		cfg.Cloudflare.Variants = []string{constants.DefaultVariant}
+	}
+	if cfg.Cloudflare.UsageAlert.Thresholds == nil {
+		cfg.Cloudflare.UsageAlert.Thresholds = slices.Clone(constants.DefaultUsageAlertThresholds)
+	}
+	slices.Sort(cfg.Cloudflare.UsageAlert.Thresholds)
+	if cfg.Cloudflare.UsageAlert.CheckInterval <= 0 {
+		cfg.Cloudflare.UsageAlert.CheckInterval = constants.DefaultUsageCheckInterval
+	}
+	setLimitDefaults(&cfg.RateLimit.Cloudflare, constants.DefaultCloudflareRate, constants.DefaultCloudflareBurst)
+	setLimitDefaults(&cfg.RateLimit.TelegramGlobal, constants.DefaultTelegramGlobalRate, constants.DefaultTelegramGlobalBurst)
+	setLimitDefaults(&cfg.RateLimit.TelegramPerChat, constants.DefaultTelegramChatRate, constants.DefaultTelegramChatBurst)
+	if cfg.GroupAuth.Role == RoleNone {
+		cfg.GroupAuth.Role = DefaultRole
+	}
+	if cfg.GroupAuth.CacheTTL <= 0 {
+		cfg.GroupAuth.CacheTTL = constants.DefaultMembershipCacheTTL
+	}
+	if cfg.Language.Default == "" {
+		cfg.Language.Default = constants.DefaultLanguage
+	}
+	if cfg.Storage.DataDir == "" {
+		cfg.Storage.DataDir = constants.DefaultDataDir
+	}
+	if cfg.Upload.Workers <= 0 {
+		cfg.Upload.Workers = constants.DefaultUploadWorkers
+	}
+	if cfg.Upload.QueueSize <= 0 {
+		cfg.Upload.QueueSize = constants.DefaultUploadQueueSize
+	}
+	if cfg.Upload.PendingTTL <= 0 {
+		cfg.Upload.PendingTTL = constants.DefaultPendingTTL
+	}
 
 	return cfg, nil
 }
@@ -92,11 +234,35 @@ func (c *Config) Validate() error {
 		return apperrors.New(apperrors.ErrInvalidConfig, "cloudflare.api_token is required")
 	}
 
+	if c.GroupAuth.Role != RoleNone && !IsAssignable(c.GroupAuth.Role) {
+		return apperrors.New(apperrors.ErrInvalidConfig, fmt.Sprintf("invalid group_auth.role %q", c.GroupAuth.Role))
+	}
+
+	for _, threshold := range c.Cloudflare.UsageAlert.Thresholds {
+		if threshold <= 0 || threshold > 100 {
+			return apperrors.New(apperrors.ErrInvalidConfig, fmt.Sprintf("invalid cloudflare.usage_alert threshold %d, must be 1-100", threshold))
+		}
+	}
+
+	for userID, role := range c.Roles {
+		if _, err := ParseRole(string(role)); err != nil {
+			return apperrors.Wrap(apperrors.ErrInvalidConfig, fmt.Sprintf("invalid role for user %d", userID), err)
+		}
+	}
+
 	return nil
 }
 
 // Save persists the configuration to disk.
 func (c *Config) Save() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.save()
+}
+
+// save writes the configuration; callers must hold c.mu.
+func (c *Config) save() error {
 	if c.configPath == "" {
 		return apperrors.New(apperrors.ErrInvalidConfig, "config path not set")
 	}
@@ -123,43 +289,204 @@ func (c *Config) SetConfigPath(path string) {
 	c.configPath = path
 }
 
-// IsAuthorized checks if a user ID is in the authorized list or is admin.
-func (c *Config) IsAuthorized(userID int64) bool {
-	if userID == c.AdminID {
+// isAuthorized reports whether a user is an admin or holds an unexpired
+// authorization; callers must hold c.mu.
+func (c *Config) isAuthorized(userID int64) bool {
+	if c.isAdmin(userID) {
 		return true
 	}
 
 	for _, id := range c.AuthorizedUsers {
 		if id == userID {
-			return true
+			return !c.isExpired(userID, time.Now())
 		}
 	}
 
 	return false
 }
 
-// IsAdmin checks if a user ID is the admin.
-func (c *Config) IsAdmin(userID int64) bool {
-	return userID == c.AdminID
+// isExpired reports whether a temporary authorization has lapsed; callers
+// must hold c.mu.
+func (c *Config) isExpired(userID int64, now time.Time) bool {
+	expiresAt, ok := c.AuthExpiry[userID]
+	return ok && !now.Before(expiresAt)
+}
+
+// ExpiryOf returns when a user's temporary authorization ends.
+func (c *Config) ExpiryOf(userID int64) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	expiresAt, ok := c.AuthExpiry[userID]
+	return expiresAt, ok
+}
+
+// isAdmin reports whether a user is an admin; callers must hold c.mu.
+func (c *Config) isAdmin(userID int64) bool {
+	return slices.Contains(c.Admins, userID)
+}
+
+// UserIDs returns every admin and authorized user, admins first.
+func (c *Config) UserIDs() []int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ids := slices.Clone(c.Admins)
+	for _, id := range c.AuthorizedUsers {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// AdminIDs returns a copy of the admin list.
+func (c *Config) AdminIDs() []int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.Admins)
+}
+
+// AddAdmin promotes a user to admin.
+func (c *Config) AddAdmin(userID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isAdmin(userID) {
+		return apperrors.New(apperrors.ErrAlreadyAdmin, fmt.Sprintf("user %d is already an admin", userID))
+	}
+
+	c.Admins = append(c.Admins, userID)
+	delete(c.Roles, userID)
+	delete(c.AuthExpiry, userID)
+	return c.save()
+}
+
+// RemoveAdmin demotes an admin to a regular authorized user.
+func (c *Config) RemoveAdmin(userID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.removeAdmin(userID); err != nil {
+		return err
+	}
+
+	if !slices.Contains(c.AuthorizedUsers, userID) {
+		c.AuthorizedUsers = append(c.AuthorizedUsers, userID)
+	}
+
+	return c.save()
+}
+
+// removeAdmin drops userID from the admins list, refusing to remove the
+// last admin; callers must hold c.mu.
+func (c *Config) removeAdmin(userID int64) error {
+	i := slices.Index(c.Admins, userID)
+	if i < 0 {
+		return apperrors.New(apperrors.ErrNotAdmin, fmt.Sprintf("user %d is not an admin", userID))
+	}
+
+	if len(c.Admins) == 1 {
+		return apperrors.New(apperrors.ErrLastAdmin, "cannot remove the last admin")
+	}
+
+	c.Admins = slices.Delete(c.Admins, i, i+1)
+	return nil
 }
 
 // AddAuthorizedUser adds a user to the authorized list.
 func (c *Config) AddAuthorizedUser(userID int64) error {
-	if c.IsAuthorized(userID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isAdmin(userID) {
 		return apperrors.New(apperrors.ErrUserAlreadyExists, fmt.Sprintf("user %d is already authorized", userID))
 	}
 
+	if slices.Contains(c.AuthorizedUsers, userID) {
+		if _, temporary := c.AuthExpiry[userID]; !temporary {
+			return apperrors.New(apperrors.ErrUserAlreadyExists, fmt.Sprintf("user %d is already authorized", userID))
+		}
+
+		// Re-authorizing a temporary user makes the grant permanent
+		delete(c.AuthExpiry, userID)
+		return c.save()
+	}
+
+	// Drop any expiry left over from an earlier temporary grant
+	delete(c.AuthExpiry, userID)
 	c.AuthorizedUsers = append(c.AuthorizedUsers, userID)
-	return c.Save()
+	return c.save()
+}
+
+// AddAuthorizedUserUntil authorizes a user until expiresAt. An existing
+// user's expiry is replaced, so this also extends or shortens a grant. The
+// returned flag reports whether the user previously had permanent access,
+// which this call made temporary.
+func (c *Config) AddAuthorizedUserUntil(userID int64, expiresAt time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isAdmin(userID) {
+		return false, apperrors.New(apperrors.ErrTargetIsAdmin, "cannot set an expiry on an admin")
+	}
+
+	_, temporary := c.AuthExpiry[userID]
+	wasPermanent := slices.Contains(c.AuthorizedUsers, userID) && !temporary
+	if !slices.Contains(c.AuthorizedUsers, userID) {
+		c.AuthorizedUsers = append(c.AuthorizedUsers, userID)
+	}
+
+	if c.AuthExpiry == nil {
+		c.AuthExpiry = make(map[int64]time.Time)
+	}
+	c.AuthExpiry[userID] = expiresAt
+
+	return wasPermanent, c.save()
+}
+
+// RemoveExpiredUsers removes users whose temporary authorization has
+// lapsed and returns their IDs.
+func (c *Config) RemoveExpiredUsers(now time.Time) ([]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expired []int64
+	for userID := range c.AuthExpiry {
+		if c.isExpired(userID, now) {
+			expired = append(expired, userID)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	for _, userID := range expired {
+		c.AuthorizedUsers = slices.DeleteFunc(c.AuthorizedUsers, func(id int64) bool { return id == userID })
+		delete(c.AuthExpiry, userID)
+		delete(c.Roles, userID)
+	}
+
+	return expired, c.save()
 }
 
 // RemoveAuthorizedUser removes a user from the authorized list.
 func (c *Config) RemoveAuthorizedUser(userID int64) error {
-	if userID == c.AdminID {
-		return apperrors.New(apperrors.ErrInvalidConfig, "cannot remove admin user")
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
+	// Admins may be removed only while another admin remains
 	found := false
+	if c.isAdmin(userID) {
+		if err := c.removeAdmin(userID); err != nil {
+			return err
+		}
+		found = true
+	}
+
 	for i, id := range c.AuthorizedUsers {
 		if id == userID {
 			c.AuthorizedUsers = append(c.AuthorizedUsers[:i], c.AuthorizedUsers[i+1:]...)
@@ -172,7 +499,94 @@ func (c *Config) RemoveAuthorizedUser(userID int64) error {
 		return apperrors.New(apperrors.ErrUserNotFound, fmt.Sprintf("user %d is not in authorized list", userID))
 	}
 
-	return c.Save()
+	delete(c.Roles, userID)
+	delete(c.AuthExpiry, userID)
+	return c.save()
+}
+
+// setLimitDefaults fills in an unset rate limit. A rate of 0 counts as
+// unset; only a negative rate disables the limiter.
+func setLimitDefaults(l *LimitConfig, rate float64, burst int) {
+	if l.Rate == 0 {
+		l.Rate = rate
+	}
+	if l.Burst <= 0 {
+		l.Burst = burst
+	}
+}
+
+// QuotaFor returns the effective upload limits for a user, where 0 means
+// unlimited.
+func (c *Config) QuotaFor(userID int64) QuotaLimits {
+	limits := c.Quota.QuotaLimits
+
+	override, ok := c.Quota.Users[userID]
+	if !ok {
+		return limits
+	}
+
+	limits.DailyCount = int(mergeLimit(int64(limits.DailyCount), int64(override.DailyCount)))
+	limits.DailyMB = mergeLimit(limits.DailyMB, override.DailyMB)
+	limits.MonthlyCount = int(mergeLimit(int64(limits.MonthlyCount), int64(override.MonthlyCount)))
+	limits.MonthlyMB = mergeLimit(limits.MonthlyMB, override.MonthlyMB)
+
+	return limits
+}
+
+// PublishChannelFor returns the channel a user's uploads are posted to,
+// or 0 if they are not published.
+func (c *Config) PublishChannelFor(userID int64) int64 {
+	if channel, ok := c.Publish.Users[userID]; ok {
+		return channel
+	}
+	return c.Publish.Channel
+}
+
+// mergeLimit applies a per-user override to a global limit.
+func mergeLimit(global, override int64) int64 {
+	switch {
+	case override < 0:
+		return 0
+	case override > 0:
+		return override
+	default:
+		return global
+	}
+}
+
+// IsChatAuthorized checks if a group or channel may use the bot.
+func (c *Config) IsChatAuthorized(chatID int64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Contains(c.AuthorizedChats, chatID)
+}
+
+// AddAuthorizedChat authorizes a group or channel.
+func (c *Config) AddAuthorizedChat(chatID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if slices.Contains(c.AuthorizedChats, chatID) {
+		return apperrors.New(apperrors.ErrChatAlreadyExists, fmt.Sprintf("chat %d is already authorized", chatID))
+	}
+
+	c.AuthorizedChats = append(c.AuthorizedChats, chatID)
+	return c.save()
+}
+
+// RemoveAuthorizedChat revokes a group or channel's authorization.
+func (c *Config) RemoveAuthorizedChat(chatID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.Index(c.AuthorizedChats, chatID)
+	if i < 0 {
+		return apperrors.New(apperrors.ErrChatNotFound, fmt.Sprintf("chat %d is not authorized", chatID))
+	}
+
+	c.AuthorizedChats = slices.Delete(c.AuthorizedChats, i, i+1)
+	return c.save()
 }
 
 // findConfigFile searches for config.yaml in common locations.
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+// testConfig returns a config that saves to a temporary file.
+func testConfig(t *testing.T, admins, users []int64) *Config {
+	t.Helper()
+
+	cfg := &Config{Admins: admins, AuthorizedUsers: users}
+	cfg.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	return cfg
+}
+
+func TestLoadMigratesAdminID(t *testing.T) {
+	const required = "telegram:\n  bot_token: token\ncloudflare:\n  account_id: account\n  api_token: secret\n"
+
+	tests := []struct {
+		name  string
+		yaml  string
+		want  []int64
+		saved bool // admin_id must be gone after saving
+	}{
+		{"legacy admin only", "admin_id: 1\n", []int64{1}, true},
+		{"legacy admin added to list", "admin_id: 1\nadmins: [2, 3]\n", []int64{2, 3, 1}, true},
+		{"legacy admin already listed", "admin_id: 2\nadmins: [2, 3]\n", []int64{2, 3}, true},
+		{"admins list only", "admins: [4]\n", []int64{4}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(required+tt.yaml), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if got := cfg.AdminIDs(); !slices.Equal(got, tt.want) {
+				t.Errorf("AdminIDs() = %v, want %v", got, tt.want)
+			}
+			if cfg.AdminID != 0 {
+				t.Errorf("AdminID = %d after load, want 0", cfg.AdminID)
+			}
+			for _, id := range tt.want {
+				if cfg.RoleOf(id) != RoleAdmin {
+					t.Errorf("RoleOf(%d) = %q, want admin", id, cfg.RoleOf(id))
+				}
+			}
+
+			if err := cfg.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			data, _ := os.ReadFile(path)
+			if strings.Contains(string(data), "admin_id") {
+				t.Errorf("saved config still contains admin_id:\n%s", data)
+			}
+
+			reloaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() after save error = %v", err)
+			}
+			if got := reloaded.AdminIDs(); !slices.Equal(got, tt.want) {
+				t.Errorf("AdminIDs() after reload = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveAdminGuardsLastAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		admins     []int64
+		users      []int64
+		remove     func(*Config, int64) error
+		target     int64
+		wantErr    error
+		wantAdmins []int64
+		wantUsers  []int64
+	}{
+		{"demote last admin", []int64{1}, nil, (*Config).RemoveAdmin, 1, apperrors.ErrLastAdmin, []int64{1}, nil},
+		{"unauth last admin", []int64{1}, nil, (*Config).RemoveAuthorizedUser, 1, apperrors.ErrLastAdmin, []int64{1}, nil},
+		{"unauth last admin also listed as user", []int64{1}, []int64{1}, (*Config).RemoveAuthorizedUser, 1, apperrors.ErrLastAdmin, []int64{1}, []int64{1}},
+		{"demote keeps access", []int64{1, 2}, nil, (*Config).RemoveAdmin, 1, nil, []int64{2}, []int64{1}},
+		{"unauth removes access", []int64{1, 2}, []int64{1, 3}, (*Config).RemoveAuthorizedUser, 1, nil, []int64{2}, []int64{3}},
+		{"demote non-admin", []int64{1}, []int64{3}, (*Config).RemoveAdmin, 3, apperrors.ErrNotAdmin, []int64{1}, []int64{3}},
+		{"unauth unknown user", []int64{1}, []int64{3}, (*Config).RemoveAuthorizedUser, 4, apperrors.ErrUserNotFound, []int64{1}, []int64{3}},
+		{"unauth regular user", []int64{1}, []int64{3}, (*Config).RemoveAuthorizedUser, 3, nil, []int64{1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, slices.Clone(tt.admins), slices.Clone(tt.users))
+
+			err := tt.remove(cfg, tt.target)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !apperrors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(cfg.Admins, tt.wantAdmins) {
+				t.Errorf("Admins = %v, want %v", cfg.Admins, tt.wantAdmins)
+			}
+			if !slices.Equal(cfg.AuthorizedUsers, tt.wantUsers) {
+				t.Errorf("AuthorizedUsers = %v, want %v", cfg.AuthorizedUsers, tt.wantUsers)
+			}
+			if len(cfg.Admins) == 0 {
+				t.Error("no admins left")
+			}
+		})
+	}
+}
+
+func TestRemoveExpiredUsers(t *testing.T) {
+	// RoleOf checks expiry against the wall clock, so use it here too
+	now := time.Now()
+
+	cfg := testConfig(t, []int64{1}, []int64{10, 11, 12, 13})
+	cfg.Roles = map[int64]Role{10: RoleViewer, 11: RoleViewer}
+	cfg.AuthExpiry = map[int64]time.Time{
+		10: now.Add(-time.Hour),   // lapsed
+		11: now.Add(time.Hour),    // still valid
+		12: now,                   // ends exactly now
+		99: now.Add(-time.Minute), // stale entry for a user no longer listed
+	}
+
+	expired, err := cfg.RemoveExpiredUsers(now)
+	if err != nil {
+		t.Fatalf("RemoveExpiredUsers() error = %v", err)
+	}
+
+	slices.Sort(expired)
+	if want := []int64{10, 12, 99}; !slices.Equal(expired, want) {
+		t.Errorf("expired = %v, want %v", expired, want)
+	}
+	if want := []int64{11, 13}; !slices.Equal(cfg.AuthorizedUsers, want) {
+		t.Errorf("AuthorizedUsers = %v, want %v", cfg.AuthorizedUsers, want)
+	}
+	if _, ok := cfg.Roles[10]; ok {
+		t.Error("role of expired user 10 was kept")
+	}
+	if cfg.Roles[11] != RoleViewer {
+		t.Error("role of valid user 11 was removed")
+	}
+	if _, ok := cfg.AuthExpiry[11]; !ok || len(cfg.AuthExpiry) != 1 {
+		t.Errorf("AuthExpiry = %v, want only user 11", cfg.AuthExpiry)
+	}
+	if !slices.Equal(cfg.Admins, []int64{1}) {
+		t.Errorf("Admins = %v, want [1]", cfg.Admins)
+	}
+
+	// Nothing else has lapsed, so a second sweep finds nothing
+	expired, err = cfg.RemoveExpiredUsers(now)
+	if err != nil || len(expired) != 0 {
+		t.Errorf("second RemoveExpiredUsers() = %v, %v, want none", expired, err)
+	}
+
+	// Once the remaining grant lapses the user loses their role too
+	if cfg.RoleOf(11) != RoleViewer {
+		t.Errorf("RoleOf(11) = %q before expiry, want viewer", cfg.RoleOf(11))
+	}
+	expired, _ = cfg.RemoveExpiredUsers(now.Add(2 * time.Hour))
+	if !slices.Equal(expired, []int64{11}) {
+		t.Errorf("expired after 2h = %v, want [11]", expired)
+	}
+	if cfg.RoleOf(11) != RoleNone {
+		t.Errorf("RoleOf(11) after expiry = %q, want none", cfg.RoleOf(11))
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+// Role is a named set of permissions assigned to a user.
+type Role string
+
+// Available roles, from least to most privileged.
+const (
+	RoleNone      Role = ""
+	RoleViewer    Role = "viewer"
+	RoleUploader  Role = "uploader"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// DefaultRole is assigned to authorized users without an explicit role.
+const DefaultRole = RoleUploader
+
+// Permission is a single capability checked before an action.
+type Permission int
+
+// Permissions granted by roles.
+const (
+	PermView        Permission = iota // use the bot, list and search images
+	PermUpload                        // upload images and delete one's own
+	PermDeleteAny                     // delete images uploaded by others
+	PermManageUsers                   // authorize users and assign roles
+)
+
+// rolePermissions maps each role to the permissions it grants.
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:    {PermView},
+	RoleUploader:  {PermView, PermUpload},
+	RoleModerator: {PermView, PermUpload, PermDeleteAny},
+	RoleAdmin:     {PermView, PermUpload, PermDeleteAny, PermManageUsers},
+}
+
+// AssignableRoles lists the roles that can be given with the /role command.
+var AssignableRoles = []Role{RoleViewer, RoleUploader, RoleModerator}
+
+// ParseRole validates a role name.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := rolePermissions[role]; !ok {
+		return RoleNone, apperrors.New(apperrors.ErrInvalidRole, fmt.Sprintf("unknown role %q", name))
+	}
+	return role, nil
+}
+
+// RoleOf returns the user's role, or RoleNone if the user is not authorized.
+func (c *Config) RoleOf(userID int64) Role {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.roleOf(userID)
+}
+
+// roleOf implements RoleOf; callers must hold c.mu.
+func (c *Config) roleOf(userID int64) Role {
+	if c.isAdmin(userID) {
+		return RoleAdmin
+	}
+
+	if !c.isAuthorized(userID) {
+		return RoleNone
+	}
+
+	if role, ok := c.Roles[userID]; ok {
+		return role
+	}
+
+	return DefaultRole
+}
+
+// RoleHas reports whether role grants perm.
+func RoleHas(role Role, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAssignable reports whether role can be given to a non-admin user.
+func IsAssignable(role Role) bool {
+	for _, r := range AssignableRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRole assigns a role to a user, authorizing them if necessary.
+func (c *Config) SetRole(userID int64, role Role) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isAdmin(userID) {
+		return apperrors.New(apperrors.ErrTargetIsAdmin, "cannot change an admin's role, demote them first")
+	}
+
+	if !IsAssignable(role) {
+		return apperrors.New(apperrors.ErrInvalidRole, fmt.Sprintf("role %q cannot be assigned", role))
+	}
+
+	if !slices.Contains(c.AuthorizedUsers, userID) {
+		c.AuthorizedUsers = append(c.AuthorizedUsers, userID)
+	}
+
+	if c.Roles == nil {
+		c.Roles = make(map[int64]Role)
+	}
+
+	if role == DefaultRole {
+		delete(c.Roles, userID)
+	} else {
+		c.Roles[userID] = role
+	}
+
+	return c.save()
+}
@@ -17,6 +17,38 @@ const (
 	HTTPClientTimeout = 30 * time.Second
 	ShutdownTimeout   = 5 * time.Second
 	ContextTimeout    = 10 * time.Second
+	DownloadTimeout   = 30 * time.Second
+	UploadTimeout     = 60 * time.Second
+)
+
+// Cloudflare retry defaults.
+const (
+	DefaultRetryAttempts       = 3
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	DefaultRetryMaxBackoff     = 10 * time.Second
+)
+
+// Cloudflare usage alert defaults.
+const DefaultUsageCheckInterval = time.Hour
+
+// DefaultUsageAlertThresholds are the percentages of the account's allowed
+// images at which admins are warned.
+var DefaultUsageAlertThresholds = []int{80, 95}
+
+// DefaultLanguage is used for users whose language has no catalog.
+const DefaultLanguage = "zh"
+
+// DefaultVariant is the Cloudflare Images variant every account starts with.
+const DefaultVariant = "public"
+
+// Rate limit defaults, in requests per second.
+const (
+	DefaultCloudflareRate      = 4.0
+	DefaultCloudflareBurst     = 8
+	DefaultTelegramGlobalRate  = 25.0
+	DefaultTelegramGlobalBurst = 30
+	DefaultTelegramChatRate    = 1.0
+	DefaultTelegramChatBurst   = 3
 )
 
 // File naming.
@@ -32,6 +64,58 @@ const (
 	UpdateInterval     = 60 // seconds for polling interval
 )
 
+// Group membership authorization.
+const (
+	DefaultMembershipCacheTTL = 5 * time.Minute
+)
+
+// Invite code defaults.
+const (
+	DefaultInviteTTL  = 7 * 24 * time.Hour
+	DefaultInviteUses = 1
+	InviteCodeBytes   = 8
+)
+
+// Temporary authorization settings.
+const (
+	ExpirySweepInterval = time.Minute
+	MaxGrantDuration    = 10 * 365 * 24 * time.Hour // longest duration accepted by /auth and /invite
+)
+
+// Access request settings.
+const (
+	AccessRequestCooldown = time.Hour      // wait after a denial before re-requesting
+	AccessRequestTTL      = 24 * time.Hour // undecided requests may be repeated after this
+)
+
+// Storage settings.
+const (
+	DefaultDataDir    = "data"
+	UserFlushInterval = 30 * time.Second
+	UsersPageSize     = 10
+	ImagesListLimit   = 10 // newest matches shown by /images
+)
+
+// Upload queue settings.
+const (
+	DefaultUploadWorkers   = 2
+	DefaultUploadQueueSize = 50
+	DefaultPendingTTL      = 30 * time.Minute
+	PendingSweepInterval   = time.Minute
+	PendingTokenBytes      = 6
+)
+
+// Upload statistics settings.
+const (
+	StatsRetention    = 30 * 24 * time.Hour
+	StatsTopUploaders = 5
+	StatsCompactLines = 1000 // expired log lines tolerated before rewriting the log
+)
+
+// ErrorRefBytes is the number of random bytes in the reference ID shown to
+// users alongside an error, so it can be looked up in the logs.
+const ErrorRefBytes = 3
+
 // HTTP status codes for logging.
 const (
 	StatusOK           = 200
@@ -21,6 +21,25 @@ var (
 	ErrInvalidUserID     = errors.New("invalid user ID format")
 	ErrMissingFileID     = errors.New("no pending upload found")
 	ErrCloudflareAPI     = errors.New("cloudflare API error")
+	ErrQueueFull         = errors.New("upload queue is full")
+	ErrQueueClosed       = errors.New("upload queue is closed")
+	ErrUploadCanceled    = errors.New("upload canceled by user")
+	ErrShuttingDown      = errors.New("bot is shutting down")
+	ErrStorage           = errors.New("storage error")
+	ErrQuotaExceeded     = errors.New("upload quota exceeded")
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidDuration   = errors.New("invalid duration")
+	ErrInviteNotFound    = errors.New("invite code not found")
+	ErrInviteExpired     = errors.New("invite code expired")
+	ErrInviteExhausted   = errors.New("invite code used up")
+	ErrAlreadyAdmin      = errors.New("user is already an admin")
+	ErrNotAdmin          = errors.New("user is not an admin")
+	ErrLastAdmin         = errors.New("cannot remove the last admin")
+	ErrTargetIsAdmin     = errors.New("operation not allowed on an admin")
+	ErrChatAlreadyExists = errors.New("chat already authorized")
+	ErrChatNotFound      = errors.New("chat not in authorized list")
+	ErrImageNotFound     = errors.New("image not found")
+	ErrDeleteFailed      = errors.New("image deletion failed")
 )
 
 // AppError represents an application-specific error with context.
@@ -41,6 +60,12 @@ func (e *AppError) Unwrap() error {
 	return e.Cause
 }
 
+// Is reports whether the error's type matches target, so sentinel
+// comparisons work with errors.Is.
+func (e *AppError) Is(target error) bool {
+	return e.Type == target
+}
+
 // New creates a new application error.
 func New(errType error, message string) *AppError {
 	return &AppError{
@@ -0,0 +1,139 @@
+// Package i18n provides message catalogs for user-facing text.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+//go:embed locales/*.yaml
+var builtin embed.FS
+
+// Catalog holds translated messages for every supported language. Each
+// language is one YAML file named after its code, mapping message keys
+// to texts; texts may contain fmt verbs filled from arguments.
+type Catalog struct {
+	messages map[string]map[string]string
+	fallback string
+}
+
+// Load reads the built-in catalogs and any *.yaml files in dir, which may
+// add languages or override built-in texts. Texts missing from a language
+// fall back to the fallback language.
+func Load(fallback, dir string) (*Catalog, error) {
+	c := &Catalog{
+		messages: make(map[string]map[string]string),
+		fallback: fallback,
+	}
+
+	if err := c.loadFS(builtin, "locales"); err != nil {
+		return nil, err
+	}
+
+	if dir != "" {
+		if err := c.loadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := c.messages[fallback]; !ok {
+		return nil, apperrors.New(apperrors.ErrInvalidConfig, fmt.Sprintf("no catalog for default language %q", fallback))
+	}
+
+	return c, nil
+}
+
+// loadFS merges every *.yaml file in dir of fsys into the catalog.
+func (c *Catalog) loadFS(fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrInvalidConfig, "failed to list message catalogs", err)
+	}
+
+	for _, name := range paths {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return apperrors.Wrap(apperrors.ErrInvalidConfig, "failed to read "+name, err)
+		}
+
+		var messages map[string]string
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return apperrors.Wrap(apperrors.ErrInvalidConfig, "failed to parse "+name, err)
+		}
+
+		lang := strings.TrimSuffix(path.Base(name), ".yaml")
+		if c.messages[lang] == nil {
+			c.messages[lang] = make(map[string]string)
+		}
+		for key, text := range messages {
+			c.messages[lang][key] = text
+		}
+	}
+
+	return nil
+}
+
+// Text returns the message for key in lang, formatted with args.
+func (c *Catalog) Text(lang, key string, args ...interface{}) string {
+	text, ok := c.messages[lang][key]
+	if !ok {
+		text, ok = c.messages[c.fallback][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Default returns the fallback language.
+func (c *Catalog) Default() string {
+	return c.fallback
+}
+
+// Has reports whether lang has a catalog.
+func (c *Catalog) Has(lang string) bool {
+	_, ok := c.messages[lang]
+	return ok
+}
+
+// Match returns the supported language for a Telegram language_code such
+// as "en-US", or the fallback language if there is none.
+func (c *Catalog) Match(code string) string {
+	code = strings.ToLower(code)
+	if c.Has(code) {
+		return code
+	}
+
+	if base, _, ok := strings.Cut(code, "-"); ok && c.Has(base) {
+		return base
+	}
+
+	return c.fallback
+}
+
+// Languages returns the supported languages in a stable order, the
+// fallback language first.
+func (c *Catalog) Languages() []string {
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		if lang != c.fallback {
+			langs = append(langs, lang)
+		}
+	}
+	slices.Sort(langs)
+
+	return append([]string{c.fallback}, langs...)
+}
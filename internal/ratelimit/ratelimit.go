@@ -0,0 +1,191 @@
+// Package ratelimit provides token-bucket rate limiters.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleTimeout is how long an unused keyed limiter is kept before pruning.
+const idleTimeout = 10 * time.Minute
+
+// Limiter is a token bucket refilled at a fixed rate up to burst tokens.
+// A zero or negative rate disables limiting.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// New creates a limiter allowing rate events per second with the given burst.
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.refund()
+		return ctx.Err()
+	}
+}
+
+// Allow takes a token if one is available now, without waiting or going
+// into debt, and reports whether it did.
+func (l *Limiter) Allow() bool {
+	if l.rate <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
+
+// reserve takes a token, possibly going into debt, and returns how long
+// the caller must wait before the token is actually available.
+func (l *Limiter) reserve() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(time.Now())
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// refund returns a reserved token that was never used.
+func (l *Limiter) refund() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// advance refills tokens for the time elapsed since the last update.
+func (l *Limiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+
+	l.tokens += elapsed * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// idle reports whether the bucket has been unused since cutoff and has
+// refilled to burst by now, so dropping it loses no state.
+func (l *Limiter) idle(now, cutoff time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.Before(cutoff) {
+		return false
+	}
+
+	l.advance(now)
+	return l.tokens >= l.burst
+}
+
+// KeyedLimiter maintains an independent limiter per key, such as a chat ID.
+type KeyedLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     int
+	limiters  map[int64]*Limiter
+	lastPrune time.Time
+}
+
+// NewKeyed creates a keyed limiter where each key gets rate and burst.
+func NewKeyed(rate float64, burst int) *KeyedLimiter {
+	return &KeyedLimiter{
+		rate:      rate,
+		burst:     burst,
+		limiters:  make(map[int64]*Limiter),
+		lastPrune: time.Now(),
+	}
+}
+
+// Wait blocks until a token for key is available or ctx is done.
+func (k *KeyedLimiter) Wait(ctx context.Context, key int64) error {
+	if k.rate <= 0 {
+		return nil
+	}
+
+	return k.get(key).Wait(ctx)
+}
+
+// Allow takes a token for key if one is available now, without waiting.
+func (k *KeyedLimiter) Allow(key int64) bool {
+	if k.rate <= 0 {
+		return true
+	}
+
+	return k.get(key).Allow()
+}
+
+// get returns the limiter for key, creating it and pruning idle ones as needed.
+func (k *KeyedLimiter) get(key int64) *Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(k.lastPrune) > idleTimeout {
+		cutoff := now.Add(-idleTimeout)
+		for id, l := range k.limiters {
+			if l.idle(now, cutoff) {
+				delete(k.limiters, id)
+			}
+		}
+		k.lastPrune = now
+	}
+
+	l, ok := k.limiters[key]
+	if !ok {
+		l = New(k.rate, k.burst)
+		k.limiters[key] = l
+	}
+
+	return l
+}
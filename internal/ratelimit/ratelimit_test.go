@@ -0,0 +1,174 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// slowRate refills one token per hour so tests are not affected by elapsed time.
+const slowRate = 1.0 / 3600
+
+func TestLimiterReserveDebt(t *testing.T) {
+	tests := []struct {
+		name   string
+		burst  int
+		takes  int
+		wantAt time.Duration
+	}{
+		{"within burst", 2, 2, 0},
+		{"first token of debt", 2, 3, time.Hour},
+		{"debt accumulates", 2, 5, 3 * time.Hour},
+		{"zero burst treated as one", 0, 2, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(slowRate, tt.burst)
+
+			var delay time.Duration
+			for i := 0; i < tt.takes; i++ {
+				delay = l.reserve()
+			}
+
+			if diff := delay - tt.wantAt; diff < -time.Second || diff > time.Second {
+				t.Errorf("reserve() after %d takes = %v, want ~%v", tt.takes, delay, tt.wantAt)
+			}
+		})
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		burst   int
+		takes   int
+		refunds int
+		want    bool
+	}{
+		{"refund restores token", 1, 1, 1, true},
+		{"refund clears debt", 1, 2, 2, true},
+		{"partial refund stays in debt", 1, 3, 1, false},
+		{"refund capped at burst", 1, 0, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(slowRate, tt.burst)
+			for i := 0; i < tt.takes; i++ {
+				l.reserve()
+			}
+			for i := 0; i < tt.refunds; i++ {
+				l.refund()
+			}
+
+			if got := l.Allow(); got != tt.want {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+			if l.tokens > l.burst {
+				t.Errorf("tokens = %v, exceeds burst %v", l.tokens, l.burst)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		calls int
+		want  []bool
+	}{
+		{"burst then deny", slowRate, 2, 4, []bool{true, true, false, false}},
+		{"zero rate disabled", 0, 1, 3, []bool{true, true, true}},
+		{"negative rate disabled", -1, 1, 3, []bool{true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rate, tt.burst)
+			for i := 0; i < tt.calls; i++ {
+				if got := l.Allow(); got != tt.want[i] {
+					t.Errorf("Allow() call %d = %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+
+			// A denied Allow must not leave the bucket in debt.
+			if l.tokens < 0 {
+				t.Errorf("tokens = %v, want >= 0", l.tokens)
+			}
+		})
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := New(slowRate, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() with a token available = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait() = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The cancelled wait must give its token back instead of leaving debt.
+	if l.tokens < -0.01 {
+		t.Errorf("tokens after cancelled Wait = %v, want ~0", l.tokens)
+	}
+}
+
+func TestKeyedLimiterAllow(t *testing.T) {
+	k := NewKeyed(slowRate, 1)
+
+	if !k.Allow(1) {
+		t.Fatal("Allow(1) first call = false, want true")
+	}
+	if k.Allow(1) {
+		t.Error("Allow(1) second call = true, want false")
+	}
+	if !k.Allow(2) {
+		t.Error("Allow(2) = false, want true: keys must not share a bucket")
+	}
+
+	disabled := NewKeyed(0, 1)
+	for i := 0; i < 3; i++ {
+		if !disabled.Allow(1) {
+			t.Fatalf("disabled Allow(1) call %d = false, want true", i+1)
+		}
+	}
+}
+
+func TestLimiterIdle(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-idleTimeout)
+
+	tests := []struct {
+		name   string
+		rate   float64
+		tokens float64
+		last   time.Time
+		want   bool
+	}{
+		{"full and unused", 1, 2, cutoff.Add(-time.Second), true},
+		{"refilled while unused", 1, -5, cutoff.Add(-time.Second), true},
+		{"still in debt", slowRate, -1, cutoff.Add(-time.Second), false},
+		{"partly refilled", slowRate, 1, cutoff.Add(-time.Second), false},
+		{"used recently", 1, 2, now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rate, 2)
+			l.tokens = tt.tokens
+			l.last = tt.last
+
+			if got := l.idle(now, cutoff); got != tt.want {
+				t.Errorf("idle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// alertState is the on-disk layout of AlertStore.
+type alertState struct {
+	UsageLevel int `json:"usage_level"` // highest usage threshold already reported
+}
+
+// AlertStore remembers which warnings admins have already received, so
+// they are not repeated after a restart.
+type AlertStore struct {
+	mu    sync.Mutex
+	path  string
+	state alertState
+}
+
+// NewAlertStore loads alert state from dir.
+func NewAlertStore(dir string) (*AlertStore, error) {
+	s := &AlertStore{path: filepath.Join(dir, "alerts.json")}
+
+	if err := loadJSON(s.path, &s.state); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// UsageLevel returns the highest usage threshold already reported.
+func (s *AlertStore) UsageLevel() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.UsageLevel
+}
+
+// SetUsageLevel records the usage threshold last reported.
+func (s *AlertStore) SetUsageLevel(level int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.UsageLevel = level
+	return saveJSON(s.path, s.state)
+}
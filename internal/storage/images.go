@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+// Image is an uploaded image, kept so it can be listed, searched and
+// deleted by its owner.
+type Image struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Filename   string    `json:"filename,omitempty"`
+	URL        string    `json:"url"`
+	Format     string    `json:"format,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// matches reports whether every term occurs in the image's ID, filename
+// or tags, ignoring case.
+func (i *Image) matches(terms []string) bool {
+	text := strings.ToLower(i.ID + " " + i.Filename + " " + strings.Join(i.Tags, " "))
+	for _, term := range terms {
+		if !strings.Contains(text, strings.ToLower(strings.TrimPrefix(term, "#"))) {
+			return false
+		}
+	}
+	return true
+}
+
+// ImageStore persists the images uploaded through the bot.
+type ImageStore struct {
+	mu     sync.Mutex
+	path   string
+	images map[string]*Image
+}
+
+// NewImageStore loads uploaded images from dir.
+func NewImageStore(dir string) (*ImageStore, error) {
+	s := &ImageStore{
+		path:   filepath.Join(dir, "images.json"),
+		images: make(map[string]*Image),
+	}
+
+	if err := loadJSON(s.path, &s.images); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Add stores an uploaded image.
+func (s *ImageStore) Add(image Image) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.images[image.ID] = &image
+	return saveJSON(s.path, s.images)
+}
+
+// Get returns the image with the given ID.
+func (s *ImageStore) Get(id string) (Image, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if image, ok := s.images[id]; ok {
+		return *image, true
+	}
+	return Image{}, false
+}
+
+// Remove forgets a deleted image.
+func (s *ImageStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.images[id]; !ok {
+		return apperrors.New(apperrors.ErrImageNotFound, fmt.Sprintf("image %q does not exist", id))
+	}
+
+	delete(s.images, id)
+	return saveJSON(s.path, s.images)
+}
+
+// Search returns the images matching every term, newest first. A non-zero
+// userID restricts the results to that user's uploads.
+func (s *ImageStore) Search(terms []string, userID int64) []Image {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []Image
+	for _, image := range s.images {
+		if (userID == 0 || image.UserID == userID) && image.matches(terms) {
+			found = append(found, *image)
+		}
+	}
+
+	slices.SortFunc(found, func(a, b Image) int {
+		if c := b.UploadedAt.Compare(a.UploadedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return found
+}
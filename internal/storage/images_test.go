@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestImageStoreSearch(t *testing.T) {
+	s, err := NewImageStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewImageStore() error = %v", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	images := []Image{
+		{ID: "a1", UserID: 1, Filename: "logo.png", Tags: []string{"brand"}, UploadedAt: base},
+		{ID: "b2", UserID: 2, Filename: "Holiday.jpg", Tags: []string{"team", "beach"}, UploadedAt: base.Add(time.Hour)},
+		{ID: "c3", UserID: 1, Filename: "beach-logo.jpg", UploadedAt: base.Add(2 * time.Hour)},
+	}
+	for _, image := range images {
+		if err := s.Add(image); err != nil {
+			t.Fatalf("Add(%s) error = %v", image.ID, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		terms  []string
+		userID int64
+		want   []string
+	}{
+		{"all newest first", nil, 0, []string{"c3", "b2", "a1"}},
+		{"by user", nil, 1, []string{"c3", "a1"}},
+		{"filename ignores case", []string{"holiday"}, 0, []string{"b2"}},
+		{"tag with hash", []string{"#beach"}, 0, []string{"c3", "b2"}},
+		{"every term must match", []string{"logo", "beach"}, 0, []string{"c3"}},
+		{"by id", []string{"a1"}, 0, []string{"a1"}},
+		{"term and user", []string{"beach"}, 2, []string{"b2"}},
+		{"no match", []string{"missing"}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, image := range s.Search(tt.terms, tt.userID) {
+				got = append(got, image.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%v, %d) = %v, want %v", tt.terms, tt.userID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageStoreRemovePersists(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewImageStore(dir)
+	if err != nil {
+		t.Fatalf("NewImageStore() error = %v", err)
+	}
+
+	s.Add(Image{ID: "a1", UserID: 1})
+	s.Add(Image{ID: "b2", UserID: 2})
+	if err := s.Remove("a1"); err != nil {
+		t.Fatalf("Remove(a1) error = %v", err)
+	}
+	if err := s.Remove("a1"); err == nil {
+		t.Error("Remove(a1) twice error = nil, want not found")
+	}
+
+	reloaded, err := NewImageStore(dir)
+	if err != nil {
+		t.Fatalf("NewImageStore() reload error = %v", err)
+	}
+	if _, ok := reloaded.Get("a1"); ok {
+		t.Error("removed image a1 still stored after reload")
+	}
+	if image, ok := reloaded.Get("b2"); !ok || image.UserID != 2 {
+		t.Errorf("Get(b2) after reload = %+v, %v", image, ok)
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+// Invite is a code that authorizes whoever redeems it.
+type Invite struct {
+	Code       string    `json:"code"`
+	CreatedBy  int64     `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	MaxUses    int       `json:"max_uses"`
+	RedeemedBy []int64   `json:"redeemed_by,omitempty"`
+}
+
+// Remaining returns how many more times the invite can be redeemed.
+func (i *Invite) Remaining() int {
+	return i.MaxUses - len(i.RedeemedBy)
+}
+
+// InviteStore persists invite codes.
+type InviteStore struct {
+	mu      sync.Mutex
+	path    string
+	invites map[string]*Invite
+}
+
+// NewInviteStore loads invites from dir.
+func NewInviteStore(dir string) (*InviteStore, error) {
+	s := &InviteStore{
+		path:    filepath.Join(dir, "invites.json"),
+		invites: make(map[string]*Invite),
+	}
+
+	if err := loadJSON(s.path, &s.invites); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Add stores a new invite.
+func (s *InviteStore) Add(invite *Invite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.invites[invite.Code] = invite
+	s.prune(time.Now())
+
+	return saveJSON(s.path, s.invites)
+}
+
+// Redeem consumes one use of code for userID.
+func (s *InviteStore) Redeem(code string, userID int64, now time.Time) (*Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.invites[code]
+	if !ok {
+		return nil, apperrors.New(apperrors.ErrInviteNotFound, fmt.Sprintf("invite %q does not exist", code))
+	}
+
+	if now.After(invite.ExpiresAt) {
+		return nil, apperrors.New(apperrors.ErrInviteExpired, fmt.Sprintf("invite %q expired at %s", code, invite.ExpiresAt))
+	}
+
+	if invite.Remaining() <= 0 {
+		return nil, apperrors.New(apperrors.ErrInviteExhausted, fmt.Sprintf("invite %q has no uses left", code))
+	}
+
+	invite.RedeemedBy = append(invite.RedeemedBy, userID)
+	redeemed := *invite
+
+	if invite.Remaining() <= 0 {
+		delete(s.invites, code)
+	}
+	s.prune(now)
+
+	return &redeemed, saveJSON(s.path, s.invites)
+}
+
+// prune drops expired invites; callers must hold s.mu.
+func (s *InviteStore) prune(now time.Time) {
+	for code, invite := range s.invites {
+		if now.After(invite.ExpiresAt) {
+			delete(s.invites, code)
+		}
+	}
+}
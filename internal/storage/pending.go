@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PendingConfirmation is a compressed photo waiting for the user to
+// confirm the upload.
+type PendingConfirmation struct {
+	Token     string    `json:"token"`
+	UserID    int64     `json:"user_id"`
+	FileID    string    `json:"file_id"`
+	Caption   string    `json:"caption,omitempty"`
+	ChatID    int64     `json:"chat_id"`
+	PromptID  int       `json:"prompt_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QueuedUpload is an upload accepted into the queue but not yet finished.
+type QueuedUpload struct {
+	ID       string    `json:"id"`
+	UserID   int64     `json:"user_id"`
+	Username string    `json:"username,omitempty"`
+	Uploader string    `json:"uploader,omitempty"`
+	ChatID   int64     `json:"chat_id"`
+	ChatType string    `json:"chat_type,omitempty"`
+	FileID   string    `json:"file_id"`
+	Tags     []string  `json:"tags,omitempty"`
+	StatusID int       `json:"status_id,omitempty"`
+	QueuedAt time.Time `json:"queued_at"`
+	Sending  bool      `json:"sending,omitempty"` // the image may have reached Cloudflare
+}
+
+// pendingState is the on-disk layout of PendingStore.
+type pendingState struct {
+	Confirmations map[string]*PendingConfirmation `json:"confirmations"`
+	Uploads       map[string]*QueuedUpload        `json:"uploads"`
+}
+
+// PendingStore persists confirmations and queued uploads so they survive
+// restarts.
+type PendingStore struct {
+	mu    sync.Mutex
+	path  string
+	state pendingState
+}
+
+// NewPendingStore loads pending state from dir.
+func NewPendingStore(dir string) (*PendingStore, error) {
+	s := &PendingStore{path: filepath.Join(dir, "pending.json")}
+
+	if err := loadJSON(s.path, &s.state); err != nil {
+		return nil, err
+	}
+
+	if s.state.Confirmations == nil {
+		s.state.Confirmations = make(map[string]*PendingConfirmation)
+	}
+	if s.state.Uploads == nil {
+		s.state.Uploads = make(map[string]*QueuedUpload)
+	}
+
+	return s, nil
+}
+
+// AddConfirmation stores a pending confirmation.
+func (s *PendingStore) AddConfirmation(confirmation *PendingConfirmation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Confirmations[confirmation.Token] = confirmation
+	return saveJSON(s.path, s.state)
+}
+
+// RemoveConfirmation deletes the confirmation with the given token.
+func (s *PendingStore) RemoveConfirmation(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.state.Confirmations[token]; !ok {
+		return nil
+	}
+
+	delete(s.state.Confirmations, token)
+	return saveJSON(s.path, s.state)
+}
+
+// Confirmations returns every stored confirmation, oldest first.
+func (s *PendingStore) Confirmations() []PendingConfirmation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	confirmations := make([]PendingConfirmation, 0, len(s.state.Confirmations))
+	for _, c := range s.state.Confirmations {
+		confirmations = append(confirmations, *c)
+	}
+
+	slices.SortFunc(confirmations, func(a, b PendingConfirmation) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return confirmations
+}
+
+// AddUpload stores a queued upload.
+func (s *PendingStore) AddUpload(upload *QueuedUpload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Uploads[upload.ID] = upload
+	return saveJSON(s.path, s.state)
+}
+
+// RemoveUpload deletes the queued upload with the given ID.
+func (s *PendingStore) RemoveUpload(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.state.Uploads[id]; !ok {
+		return nil
+	}
+
+	delete(s.state.Uploads, id)
+	return saveJSON(s.path, s.state)
+}
+
+// MarkSending records that the upload with the given ID is being sent to
+// Cloudflare, so it is not repeated if the bot stops before it finishes.
+func (s *PendingStore) MarkSending(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.state.Uploads[id]
+	if !ok {
+		return nil
+	}
+
+	upload.Sending = true
+	return saveJSON(s.path, s.state)
+}
+
+// Uploads returns every stored upload in queue order.
+func (s *PendingStore) Uploads() []QueuedUpload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploads := make([]QueuedUpload, 0, len(s.state.Uploads))
+	for _, u := range s.state.Uploads {
+		uploads = append(uploads, *u)
+	}
+
+	slices.SortFunc(uploads, func(a, b QueuedUpload) int {
+		return a.QueuedAt.Compare(b.QueuedAt)
+	})
+
+	return uploads
+}
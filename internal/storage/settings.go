@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// UserSettings holds a user's upload preferences. The zero value is the
+// default behaviour.
+type UserSettings struct {
+	AutoUpload  bool   `json:"auto_upload,omitempty"`  // upload compressed photos without asking
+	Variant     string `json:"variant,omitempty"`      // preferred Cloudflare variant, empty for the first
+	StripEXIF   bool   `json:"strip_exif,omitempty"`   // remove EXIF data before uploading
+	ReplyFormat string `json:"reply_format,omitempty"` // format of the success message
+	Language    string `json:"language,omitempty"`     // message language, empty to follow Telegram
+}
+
+// SettingsStore persists per-user settings.
+type SettingsStore struct {
+	mu       sync.Mutex
+	path     string
+	settings map[int64]*UserSettings
+}
+
+// NewSettingsStore loads user settings from dir.
+func NewSettingsStore(dir string) (*SettingsStore, error) {
+	s := &SettingsStore{
+		path:     filepath.Join(dir, "settings.json"),
+		settings: make(map[int64]*UserSettings),
+	}
+
+	if err := loadJSON(s.path, &s.settings); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get returns a user's settings.
+func (s *SettingsStore) Get(userID int64) UserSettings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if settings, ok := s.settings[userID]; ok {
+		return *settings
+	}
+
+	return UserSettings{}
+}
+
+// Update applies fn to a user's settings and saves them.
+func (s *SettingsStore) Update(userID int64, fn func(*UserSettings)) (UserSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, ok := s.settings[userID]
+	if !ok {
+		settings = &UserSettings{}
+		s.settings[userID] = settings
+	}
+
+	fn(settings)
+
+	if *settings == (UserSettings{}) {
+		delete(s.settings, userID)
+	}
+
+	return *settings, saveJSON(s.path, s.settings)
+}
@@ -0,0 +1,50 @@
+// Package storage provides JSON file persistence for bot state.
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+// loadJSON reads path into v. A missing file leaves v untouched.
+func loadJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to read "+path, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to parse "+path, err)
+	}
+
+	return nil
+}
+
+// saveJSON writes v to path atomically via a temporary file.
+func saveJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to marshal "+path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to create data directory", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to write "+tmp, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to replace "+path, err)
+	}
+
+	return nil
+}
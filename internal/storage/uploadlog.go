@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	apperrors "telegram-cf-bot/internal/errors"
+)
+
+// UploadRecord is one finished upload attempt in the upload log.
+type UploadRecord struct {
+	Time     time.Time `json:"time"`
+	UserID   int64     `json:"user_id"`
+	Size     int64     `json:"size,omitempty"`
+	Format   string    `json:"format,omitempty"`
+	Duration int64     `json:"duration_ms"`
+	Error    string    `json:"error,omitempty"` // error type, empty on success
+}
+
+// UploadLog is an append-only log of upload attempts used for statistics.
+// Records are stored one JSON object per line so appending stays cheap;
+// records older than the retention period are dropped on load, and the
+// file is rewritten once enough expired lines have accumulated.
+type UploadLog struct {
+	mu           sync.Mutex
+	path         string
+	retention    time.Duration
+	compactLines int
+	records      []UploadRecord
+	lines        int              // lines in the file, including expired records
+	now          func() time.Time // clock used to stamp records
+}
+
+// NewUploadLog loads the upload log from dir, keeping records newer than
+// retention. The file is compacted whenever it holds compactLines expired
+// lines and at least as many expired lines as live ones.
+func NewUploadLog(dir string, retention time.Duration, compactLines int) (*UploadLog, error) {
+	l := &UploadLog{
+		path:         filepath.Join(dir, "uploads.jsonl"),
+		retention:    retention,
+		compactLines: compactLines,
+		now:          time.Now,
+	}
+
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrStorage, "failed to read "+l.path, err)
+	}
+
+	cutoff := time.Now().Add(-retention)
+	total := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		total++
+
+		// A torn final line from a crash is skipped rather than failing startup
+		var record UploadRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if record.Time.After(cutoff) {
+			l.records = append(l.records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrStorage, "failed to parse "+l.path, err)
+	}
+
+	// Logs written before records were stamped under the lock may be
+	// slightly out of order
+	slices.SortStableFunc(l.records, func(a, b UploadRecord) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	l.lines = total
+	if len(l.records) < total {
+		if err := l.rewrite(); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Append adds a record to the log, stamped with the current time. The
+// time is taken under the lock so records stay in time order even when
+// several workers append at once.
+func (l *UploadLog) Append(record UploadRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.Time = l.now()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to marshal upload record", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to create data directory", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to open "+l.path, err)
+	}
+	_, err = f.Write(append(data, '\n'))
+	f.Close()
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to write "+l.path, err)
+	}
+
+	// Records are stamped in append order, so expired ones are at the front
+	cutoff := record.Time.Add(-l.retention)
+	expired := 0
+	for expired < len(l.records) && !l.records[expired].Time.After(cutoff) {
+		expired++
+	}
+	l.records = append(l.records[expired:], record)
+	l.lines++
+
+	if stale := l.lines - len(l.records); stale >= l.compactLines && stale >= len(l.records) {
+		return l.rewrite()
+	}
+
+	return nil
+}
+
+// Since returns the records at or after t, oldest first.
+func (l *UploadLog) Since(t time.Time) []UploadRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i, _ := slices.BinarySearchFunc(l.records, t, func(r UploadRecord, t time.Time) int {
+		return r.Time.Compare(t)
+	})
+	return slices.Clone(l.records[i:])
+}
+
+// rewrite replaces the log file with the records kept in memory; callers
+// must hold l.mu or own l exclusively.
+func (l *UploadLog) rewrite() error {
+	var buf bytes.Buffer
+	for _, record := range l.records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return apperrors.Wrap(apperrors.ErrStorage, "failed to marshal upload record", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to write "+tmp, err)
+	}
+
+	if err := os.Rename(tmp, l.path); err != nil {
+		return apperrors.Wrap(apperrors.ErrStorage, "failed to replace "+l.path, err)
+	}
+
+	l.lines = len(l.records)
+	return nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fileLines counts the lines in the upload log file.
+func fileLines(t *testing.T, l *UploadLog) int {
+	t.Helper()
+
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestUploadLogRetentionAndCompaction(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		compactLines int
+		appends      int // one per minute
+		wantRecords  int
+		wantLines    int
+	}{
+		{"within retention", 5, 10, 10, 10},
+		{"expired kept until threshold", 10, 15, 10, 15},
+		{"compacted once stale lines dominate", 5, 20, 10, 10},
+		{"stale lines must also match live ones", 1, 11, 10, 11},
+		{"large threshold never compacts", 1000, 30, 10, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewUploadLog(t.TempDir(), 10*time.Minute, tt.compactLines)
+			if err != nil {
+				t.Fatalf("NewUploadLog() error = %v", err)
+			}
+
+			now := base
+			l.now = func() time.Time { return now }
+			for i := 0; i < tt.appends; i++ {
+				now = base.Add(time.Duration(i)*time.Minute + time.Second)
+				if err := l.Append(UploadRecord{UserID: int64(i)}); err != nil {
+					t.Fatalf("Append() error = %v", err)
+				}
+			}
+
+			if got := len(l.Since(time.Time{})); got != tt.wantRecords {
+				t.Errorf("records kept = %d, want %d", got, tt.wantRecords)
+			}
+			if got := fileLines(t, l); got != tt.wantLines {
+				t.Errorf("file lines = %d, want %d", got, tt.wantLines)
+			}
+			if l.lines != fileLines(t, l) {
+				t.Errorf("line counter = %d, file has %d", l.lines, fileLines(t, l))
+			}
+		})
+	}
+}
+
+func TestUploadLogReloadDropsExpired(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := NewUploadLog(dir, time.Hour, 1000)
+	if err != nil {
+		t.Fatalf("NewUploadLog() error = %v", err)
+	}
+
+	now := time.Now().Add(-3 * time.Hour)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		l.Append(UploadRecord{UserID: 1})
+	}
+	now = time.Now()
+	l.Append(UploadRecord{UserID: 2})
+
+	// A torn final line must not prevent loading
+	f, _ := os.OpenFile(filepath.Join(dir, "uploads.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"time":`)
+	f.Close()
+
+	reloaded, err := NewUploadLog(dir, time.Hour, 1000)
+	if err != nil {
+		t.Fatalf("NewUploadLog() reload error = %v", err)
+	}
+
+	records := reloaded.Since(time.Time{})
+	if len(records) != 1 || records[0].UserID != 2 {
+		t.Fatalf("records after reload = %+v, want only user 2", records)
+	}
+	if got := fileLines(t, reloaded); got != 1 {
+		t.Errorf("file lines after reload = %d, want 1", got)
+	}
+}
+
+func TestUploadLogConcurrentAppendsStayOrdered(t *testing.T) {
+	l, err := NewUploadLog(t.TempDir(), time.Hour, 1000)
+	if err != nil {
+		t.Fatalf("NewUploadLog() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				l.Append(UploadRecord{UserID: id})
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+
+	records := l.Since(time.Time{})
+	if len(records) != 160 {
+		t.Fatalf("records = %d, want 160", len(records))
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].Time.Before(records[i-1].Time) {
+			t.Fatalf("record %d at %v is before record %d at %v", i, records[i].Time, i-1, records[i-1].Time)
+		}
+	}
+}
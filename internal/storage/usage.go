@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Usage holds a user's upload totals for the current day and month.
+type Usage struct {
+	Day        string `json:"day"`
+	DayCount   int    `json:"day_count"`
+	DayBytes   int64  `json:"day_bytes"`
+	Month      string `json:"month"`
+	MonthCount int    `json:"month_count"`
+	MonthBytes int64  `json:"month_bytes"`
+}
+
+// reservation counts uploads that passed the quota check but have not
+// finished yet.
+type reservation struct {
+	count int
+	bytes int64
+}
+
+// UsageStore tracks per-user upload usage for quota enforcement.
+type UsageStore struct {
+	mu       sync.Mutex
+	path     string
+	users    map[int64]*Usage
+	reserved map[int64]*reservation
+}
+
+// NewUsageStore loads usage data from dir.
+func NewUsageStore(dir string) (*UsageStore, error) {
+	s := &UsageStore{
+		path:     filepath.Join(dir, "usage.json"),
+		users:    make(map[int64]*Usage),
+		reserved: make(map[int64]*reservation),
+	}
+
+	if err := loadJSON(s.path, &s.users); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get returns userID's usage as of now, with elapsed periods reset.
+func (s *UsageStore) Get(userID int64, now time.Time) Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(userID, now)
+}
+
+// get implements Get; callers must hold s.mu.
+func (s *UsageStore) get(userID int64, now time.Time) Usage {
+	u, ok := s.users[userID]
+	if !ok {
+		return Usage{Day: dayKey(now), Month: monthKey(now)}
+	}
+
+	rollover(u, now)
+	return *u
+}
+
+// Reserve passes userID's usage plus uploads still in flight plus this
+// one of size bytes to allow, and reserves the upload if allow accepts
+// it. Checking and reserving atomically keeps concurrent uploads from all
+// passing the same check. Every successful reservation must be released
+// with Release once the upload has been recorded or has failed.
+func (s *UsageStore) Reserve(userID int64, size int64, now time.Time, allow func(Usage) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.get(userID, now)
+	if r, ok := s.reserved[userID]; ok {
+		u.DayCount += r.count
+		u.DayBytes += r.bytes
+		u.MonthCount += r.count
+		u.MonthBytes += r.bytes
+	}
+
+	if !allow(u) {
+		return false
+	}
+
+	r, ok := s.reserved[userID]
+	if !ok {
+		r = &reservation{}
+		s.reserved[userID] = r
+	}
+	r.count++
+	r.bytes += size
+
+	return true
+}
+
+// Release ends a reservation made by Reserve.
+func (s *UsageStore) Release(userID int64, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reserved[userID]
+	if !ok {
+		return
+	}
+
+	r.count--
+	r.bytes -= size
+	if r.count <= 0 {
+		delete(s.reserved, userID)
+	}
+}
+
+// Record adds a successful upload of size bytes to userID's usage.
+func (s *UsageStore) Record(userID int64, size int64, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		u = &Usage{}
+		s.users[userID] = u
+	}
+
+	rollover(u, now)
+	u.DayCount++
+	u.DayBytes += size
+	u.MonthCount++
+	u.MonthBytes += size
+
+	return saveJSON(s.path, s.users)
+}
+
+// rollover resets counters whose day or month has passed.
+func rollover(u *Usage, now time.Time) {
+	if day := dayKey(now); u.Day != day {
+		u.Day = day
+		u.DayCount = 0
+		u.DayBytes = 0
+	}
+
+	if month := monthKey(now); u.Month != month {
+		u.Month = month
+		u.MonthCount = 0
+		u.MonthBytes = 0
+	}
+}
+
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func monthKey(t time.Time) string {
+	return t.Format("2006-01")
+}
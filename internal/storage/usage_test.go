@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"telegram-cf-bot/internal/config"
+)
+
+// within returns a Reserve check enforcing the count and byte limits the
+// way the bot does, where 0 means unlimited.
+func within(limits config.QuotaLimits, size int64) func(Usage) bool {
+	return func(u Usage) bool {
+		return (limits.DailyCount <= 0 || u.DayCount < limits.DailyCount) &&
+			(limits.DailyMB <= 0 || u.DayBytes+size <= limits.DailyMB) &&
+			(limits.MonthlyCount <= 0 || u.MonthCount < limits.MonthlyCount) &&
+			(limits.MonthlyMB <= 0 || u.MonthBytes+size <= limits.MonthlyMB)
+	}
+}
+
+func TestUsageStoreReserve(t *testing.T) {
+	jan31 := time.Date(2026, 1, 31, 23, 0, 0, 0, time.Local)
+
+	cfg := &config.Config{Quota: config.QuotaConfig{
+		QuotaLimits: config.QuotaLimits{DailyCount: 2, MonthlyCount: 3},
+		Users: map[int64]config.QuotaLimits{
+			2: {DailyCount: 5},   // raised daily limit, inherited monthly limit
+			3: {DailyCount: -1},  // unlimited per day
+			4: {MonthlyMB: 1000}, // byte limit on top of the global counts
+		},
+	}}
+
+	type step struct {
+		at      time.Time
+		size    int64
+		record  bool // the upload succeeds and is recorded
+		release bool // the reservation is released
+		want    bool
+	}
+
+	tests := []struct {
+		name   string
+		userID int64
+		steps  []step
+	}{
+		{"daily count", 1, []step{
+			{jan31, 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, false, false, false},
+		}},
+		{"in-flight uploads count against the limit", 1, []step{
+			{jan31, 1, false, false, true},
+			{jan31, 1, false, false, true},
+			{jan31, 1, false, false, false},
+		}},
+		{"failed upload releases its reservation", 1, []step{
+			{jan31, 1, false, true, true},
+			{jan31, 1, false, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, false, false, false},
+		}},
+		{"day rollover", 1, []step{
+			{jan31.Add(-24 * time.Hour), 1, true, true, true},
+			{jan31.Add(-24 * time.Hour), 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, false, false, false}, // monthly limit of 3 reached
+		}},
+		{"month rollover", 1, []step{
+			{jan31.Add(-48 * time.Hour), 1, true, true, true},
+			{jan31.Add(-24 * time.Hour), 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31.Add(2 * time.Hour), 1, true, true, true}, // February 1st
+			{jan31.Add(2 * time.Hour), 1, true, true, true},
+			{jan31.Add(2 * time.Hour), 1, false, false, false},
+		}},
+		{"override raises daily limit", 2, []step{
+			{jan31, 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, false, false, false}, // inherited monthly limit
+		}},
+		{"override removes daily limit", 3, []step{
+			{jan31, 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, true, true, true},
+			{jan31, 1, false, false, false},
+		}},
+		{"override adds byte limit", 4, []step{
+			{jan31, 600, true, true, true},
+			{jan31, 600, false, false, false},
+			{jan31, 400, true, true, true},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewUsageStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewUsageStore() error = %v", err)
+			}
+
+			limits := cfg.QuotaFor(tt.userID)
+			for i, st := range tt.steps {
+				got := s.Reserve(tt.userID, st.size, st.at, within(limits, st.size))
+				if got != st.want {
+					t.Fatalf("step %d: Reserve() = %v, want %v", i+1, got, st.want)
+				}
+				if !got {
+					continue
+				}
+				if st.record {
+					if err := s.Record(tt.userID, st.size, st.at); err != nil {
+						t.Fatalf("step %d: Record() error = %v", i+1, err)
+					}
+				}
+				if st.release {
+					s.Release(tt.userID, st.size)
+				}
+			}
+		})
+	}
+}
+
+func TestUsageStoreReleaseForgetsReservation(t *testing.T) {
+	s, err := NewUsageStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUsageStore() error = %v", err)
+	}
+
+	now := time.Now()
+	s.Reserve(1, 100, now, func(Usage) bool { return true })
+	s.Release(1, 100)
+	s.Release(1, 100) // releasing twice must not create negative usage
+
+	var seen Usage
+	s.Reserve(1, 0, now, func(u Usage) bool { seen = u; return false })
+	if seen.DayCount != 0 || seen.DayBytes != 0 {
+		t.Errorf("usage after release = %+v, want zero", seen)
+	}
+	if len(s.reserved) != 0 {
+		t.Errorf("reservations left = %d, want 0", len(s.reserved))
+	}
+}
+
+func TestUsageStoreConcurrentReserve(t *testing.T) {
+	s, err := NewUsageStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUsageStore() error = %v", err)
+	}
+
+	limits := config.QuotaLimits{DailyCount: 5, DailyMB: 1 << 20}
+	now := time.Now()
+
+	var granted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.Reserve(1, 1024, now, within(limits, 1024)) {
+				granted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := granted.Load(); got != int32(limits.DailyCount) {
+		t.Errorf("concurrent reservations granted = %d, want %d", got, limits.DailyCount)
+	}
+}
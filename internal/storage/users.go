@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserInfo is what the bot remembers about a Telegram user.
+type UserInfo struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username,omitempty"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	LanguageCode string    `json:"language_code,omitempty"`
+	LastSeen     time.Time `json:"last_seen"`
+	Uploads      int       `json:"uploads"`
+}
+
+// UserStore records users who have interacted with the bot. Updates are
+// kept in memory and written by Flush, since they happen on every message.
+type UserStore struct {
+	mu    sync.Mutex
+	path  string
+	users map[int64]*UserInfo
+	dirty bool
+}
+
+// NewUserStore loads known users from dir.
+func NewUserStore(dir string) (*UserStore, error) {
+	s := &UserStore{
+		path:  filepath.Join(dir, "users.json"),
+		users: make(map[int64]*UserInfo),
+	}
+
+	if err := loadJSON(s.path, &s.users); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Touch records the user's latest profile and activity time and reports
+// whether the user was seen for the first time. Telegram usernames are
+// released and reused, so claiming a username clears it from any other
+// user still recorded with it.
+func (s *UserStore) Touch(info UserInfo, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[info.ID]
+	if !ok {
+		u = &UserInfo{ID: info.ID}
+		s.users[info.ID] = u
+	}
+
+	if info.Username != "" && !strings.EqualFold(u.Username, info.Username) {
+		for _, other := range s.users {
+			if other.ID != info.ID && strings.EqualFold(other.Username, info.Username) {
+				other.Username = ""
+			}
+		}
+	}
+
+	u.Username = info.Username
+	u.FirstName = info.FirstName
+	u.LastName = info.LastName
+	u.LanguageCode = info.LanguageCode
+	u.LastSeen = now
+	s.dirty = true
+	return !ok
+}
+
+// RecordUpload increments the user's successful upload count.
+func (s *UserStore) RecordUpload(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		u = &UserInfo{ID: userID}
+		s.users[userID] = u
+	}
+
+	u.Uploads++
+	s.dirty = true
+}
+
+// Get returns what is known about a user.
+func (s *UserStore) Get(userID int64) (UserInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return UserInfo{ID: userID}, false
+	}
+
+	return *u, true
+}
+
+// IDs returns the IDs of all known users.
+func (s *UserStore) IDs() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, len(s.users))
+	for id := range s.users {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// FindByUsername looks up a user by username, ignoring case and a leading
+// @. If stale records share the username, the most recently seen wins.
+func (s *UserStore) FindByUsername(username string) (UserInfo, bool) {
+	username = strings.TrimPrefix(username, "@")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *UserInfo
+	for _, u := range s.users {
+		if u.Username == "" || !strings.EqualFold(u.Username, username) {
+			continue
+		}
+		if found == nil || u.LastSeen.After(found.LastSeen) {
+			found = u
+		}
+	}
+
+	if found == nil {
+		return UserInfo{}, false
+	}
+
+	return *found, true
+}
+
+// Flush writes pending changes to disk.
+func (s *UserStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	if err := saveJSON(s.path, s.users); err != nil {
+		return err
+	}
+
+	s.dirty = false
+	return nil
+}
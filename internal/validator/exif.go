@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// exifHeader starts the payload of a JPEG APP1 segment carrying EXIF data.
+var exifHeader = []byte("Exif\x00\x00")
+
+// StripEXIF removes EXIF segments from a JPEG image. Other formats and
+// malformed JPEGs are returned unchanged.
+func StripEXIF(imageBytes []byte) []byte {
+	if len(imageBytes) < 4 || imageBytes[0] != 0xFF || imageBytes[1] != 0xD8 {
+		return imageBytes
+	}
+
+	out := make([]byte, 0, len(imageBytes))
+	out = append(out, imageBytes[:2]...)
+
+	pos := 2
+	for pos+4 <= len(imageBytes) {
+		if imageBytes[pos] != 0xFF {
+			return imageBytes
+		}
+
+		// Any number of 0xFF fill bytes may precede a marker
+		if imageBytes[pos+1] == 0xFF {
+			pos++
+			continue
+		}
+
+		marker := imageBytes[pos+1]
+
+		// Start of scan: the rest is entropy-coded image data. End of
+		// image: only trailing bytes remain.
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		// Standalone markers carry no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, imageBytes[pos:pos+2]...)
+			pos += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(imageBytes[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(imageBytes) {
+			return imageBytes
+		}
+
+		segment := imageBytes[pos:end]
+		if marker != 0xE1 || !bytes.HasPrefix(segment[4:], exifHeader) {
+			out = append(out, segment...)
+		}
+
+		pos = end
+	}
+
+	return append(out, imageBytes[pos:]...)
+}
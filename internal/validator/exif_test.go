@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"bytes"
+	"testing"
+)
+
+// segment builds a JPEG marker segment with the given payload.
+func segment(marker byte, payload string) []byte {
+	length := len(payload) + 2
+	return append([]byte{0xFF, marker, byte(length >> 8), byte(length)}, payload...)
+}
+
+// jpeg concatenates SOI, the given parts and a minimal scan.
+func jpeg(parts ...[]byte) []byte {
+	out := []byte{0xFF, 0xD8}
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	out = append(out, segment(0xDA, "\x01\x02")...)
+	return append(out, 0x12, 0x34, 0xFF, 0x00, 0x56, 0xFF, 0xD9)
+}
+
+func TestStripEXIF(t *testing.T) {
+	exif := segment(0xE1, "Exif\x00\x00MM\x00\x2A")
+	xmp := segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x/>")
+	jfif := segment(0xE0, "JFIF\x00\x01\x01")
+	quant := segment(0xDB, "\x00\x10\x20")
+
+	truncated := jpeg(jfif, exif)
+	truncated = truncated[:2+len(jfif)+len(exif)-4]
+
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\nrest"), []byte("\x89PNG\r\n\x1a\nrest")},
+		{"too short", []byte{0xFF, 0xD8}, []byte{0xFF, 0xD8}},
+		{"no exif", jpeg(jfif, quant), jpeg(jfif, quant)},
+		{"exif removed", jpeg(jfif, exif, quant), jpeg(jfif, quant)},
+		{"only exif", jpeg(exif), jpeg()},
+		{"xmp kept", jpeg(exif, xmp), jpeg(xmp)},
+		{"empty app1 kept", jpeg(segment(0xE1, "")), jpeg(segment(0xE1, ""))},
+		{"fill bytes before marker", jpeg(jfif, []byte{0xFF, 0xFF}, exif, quant), jpeg(jfif, quant)},
+		{"standalone marker", jpeg([]byte{0xFF, 0xD0}, exif), jpeg([]byte{0xFF, 0xD0})},
+		{"trailing data after eoi kept", append(jpeg(exif), 0x00, 0x00), append(jpeg(), 0x00, 0x00)},
+		{"eoi before scan", append([]byte{0xFF, 0xD8}, append(exif, 0xFF, 0xD9)...), []byte{0xFF, 0xD8, 0xFF, 0xD9}},
+		{"segment past end", append([]byte{0xFF, 0xD8}, exif[:len(exif)-3]...), append([]byte{0xFF, 0xD8}, exif[:len(exif)-3]...)},
+		{"bad length", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xAA, 0xBB}, []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xAA, 0xBB}},
+		{"garbage between segments", append(jpeg(jfif), 0x00, 0xFF, 0xE1, 0x00), append(jpeg(jfif), 0x00, 0xFF, 0xE1, 0x00)},
+		{"truncated inside header", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00}, []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00}},
+		{"truncated inside exif", truncated, truncated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := bytes.Clone(tt.in)
+			got := StripEXIF(in)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("StripEXIF() = % X, want % X", got, tt.want)
+			}
+			if !bytes.Equal(in, tt.in) {
+				t.Errorf("StripEXIF() modified its input")
+			}
+		})
+	}
+}